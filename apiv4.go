@@ -0,0 +1,229 @@
+package quranc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jsteenb2/httpc"
+)
+
+// The versions of the quran.com api the client can call, set with WithAPIVersion.
+const (
+	apiV3 = "v3"
+	apiV4 = "v4"
+)
+
+// unsupportedErr returns the error of what has not been ported to the api version.
+func unsupportedErr(what, version string) error {
+	return fmt.Errorf("%s: %w %q", what, ErrUnsupportedAPIVersion, version)
+}
+
+// route returns the path serving the method in the api version the client calls, either
+// the v3 or the v4 path. An empty path is a method that has not been ported to the
+// version, an error wrapping ErrUnsupportedAPIVersion is returned for it.
+func (c *Client) route(method, v3, v4 string) (string, error) {
+	var path string
+	switch c.apiVersion {
+	case apiV3:
+		path = v3
+	case apiV4:
+		path = v4
+	}
+	if path == "" {
+		return "", unsupportedErr(method, c.apiVersion)
+	}
+	return path, nil
+}
+
+// versesQuery sets the verse options on the request as the query params of the api
+// version the client calls.
+func (c *Client) versesQuery(r *httpc.Request, opts versesReqOpt) (*httpc.Request, error) {
+	if c.apiVersion == apiV4 {
+		return opts.queryParamsV4(r)
+	}
+	return opts.queryParams(r), nil
+}
+
+// v4TextTypes are the text types the v4 api serves, by the field the text is sent in.
+var v4TextTypes = map[string]string{
+	TextTypeUthmani:       "text_uthmani",
+	TextTypeUthmaniSimple: "text_uthmani_simple",
+	TextTypeImlaei:        "text_imlaei",
+	TextTypeIndopak:       "text_indopak",
+}
+
+// queryParamsV4 sets the verse options as the query params of the v4 api. v4 pages with
+// per_page in place of limit, sends words only when asked to, and sends the verse text
+// only in the fields asked for, all of the texts it serves unless a text type is set.
+// The offset, media and related verses options have no v4 equivalent and are an error.
+func (v versesReqOpt) queryParamsV4(r *httpc.Request) (*httpc.Request, error) {
+	switch {
+	case v.Offset > 0:
+		return nil, unsupportedErr("verses offset", apiV4)
+	case len(v.Media) > 0:
+		return nil, unsupportedErr("verses media", apiV4)
+	case v.IncludeRelated:
+		return nil, unsupportedErr("related verses", apiV4)
+	}
+
+	textFields := []string{"text_uthmani", "text_uthmani_simple", "text_imlaei", "text_indopak"}
+	if v.TextType != "" {
+		field, ok := v4TextTypes[v.TextType]
+		if !ok {
+			return nil, unsupportedErr("text type "+v.TextType, apiV4)
+		}
+		textFields = []string{field}
+	}
+	r = r.QueryParam("fields", strings.Join(append([]string{"chapter_id"}, textFields...), ","))
+
+	if v.Language != "" {
+		r = r.QueryParam("language", v.Language)
+	}
+
+	if v.Recitation > 0 && !v.ExcludeAudio {
+		r = r.QueryParam("audio", strconv.Itoa(v.Recitation))
+	}
+
+	if v.Mushaf > 0 {
+		r = r.QueryParam("mushaf", strconv.Itoa(v.Mushaf))
+	}
+
+	if v.Page > 0 {
+		r = r.QueryParam("page", strconv.Itoa(v.Page))
+	}
+
+	if v.Limit > 0 {
+		r = r.QueryParam("per_page", strconv.Itoa(v.Limit))
+	}
+
+	if len(v.Translations) > 0 {
+		ids := make([]string, 0, len(v.Translations))
+		for _, id := range v.Translations {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		r = r.QueryParam("translations", strings.Join(ids, ","))
+	}
+
+	if len(v.TranslationFields) > 0 {
+		r = r.QueryParam("translation_fields", strings.Join(v.TranslationFields, ","))
+	}
+
+	if v.ExcludeWords {
+		r = r.QueryParam("words", "false")
+	} else {
+		wordFields := append(append([]string{"verse_key"}, textFields...), v.WordFields...)
+		r = r.QueryParam("words", "true").
+			QueryParam("word_fields", strings.Join(wordFields, ","))
+	}
+
+	return r, nil
+}
+
+// apiVerseV4 is a verse as the v4 api sends it.
+type apiVerseV4 struct {
+	ID                int    `json:"id"`
+	VerseNumber       int    `json:"verse_number"`
+	ChapterID         int    `json:"chapter_id"`
+	VerseKey          string `json:"verse_key"`
+	TextUthmani       string `json:"text_uthmani"`
+	TextUthmaniSimple string `json:"text_uthmani_simple"`
+	TextImlaei        string `json:"text_imlaei"`
+	TextIndopak       string `json:"text_indopak"`
+	JuzNumber         int    `json:"juz_number"`
+	HizbNumber        int    `json:"hizb_number"`
+	RubElHizbNumber   int    `json:"rub_el_hizb_number"`
+	SajdahType        string `json:"sajdah_type"`
+	SajdahNumber      int    `json:"sajdah_number"`
+	PageNumber        int    `json:"page_number"`
+	Audio             struct {
+		URL      string          `json:"url"`
+		Segments [][]json.Number `json:"segments"`
+	} `json:"audio"`
+	Translations []Resource  `json:"translations"`
+	Words        []apiWordV4 `json:"words"`
+}
+
+// verse converts the v4 verse into a Verse. The chapter id is taken from the verse key
+// when the verse was sent without it.
+func (a apiVerseV4) verse() Verse {
+	v := Verse{
+		ID:                a.ID,
+		VerseNumber:       a.VerseNumber,
+		ChapterID:         a.ChapterID,
+		VerseKey:          a.VerseKey,
+		TextUthmani:       a.TextUthmani,
+		TextUthmaniSimple: a.TextUthmaniSimple,
+		TextImlaei:        a.TextImlaei,
+		TextIndopak:       a.TextIndopak,
+		JuzNumber:         a.JuzNumber,
+		HizbNumber:        a.HizbNumber,
+		RubNumber:         a.RubElHizbNumber,
+		Sajdah:            a.SajdahType,
+		SajdahNumber:      a.SajdahNumber,
+		PageNumber:        a.PageNumber,
+		Translations:      a.Translations,
+	}
+	if key, err := ParseVerseKey(a.VerseKey); err == nil && v.ChapterID == 0 {
+		v.ChapterID = key.Chapter
+	}
+
+	v.Audio.URL = a.Audio.URL
+	for _, segment := range a.Audio.Segments {
+		s := make([]string, 0, len(segment))
+		for _, n := range segment {
+			s = append(s, n.String())
+		}
+		v.Audio.Segments = append(v.Audio.Segments, s)
+	}
+
+	if a.Words != nil {
+		v.Words = make([]Word, 0, len(a.Words))
+		for _, w := range a.Words {
+			v.Words = append(v.Words, w.word())
+		}
+	}
+	return v
+}
+
+// apiWordV4 is a word of a verse as the v4 api sends it. The text is sent in the uthmani
+// script unless other text fields are asked for.
+type apiWordV4 struct {
+	ID              int      `json:"id"`
+	Position        int      `json:"position"`
+	Text            string   `json:"text"`
+	TextUthmani     string   `json:"text_uthmani"`
+	TextImlaei      string   `json:"text_imlaei"`
+	TextIndopak     string   `json:"text_indopak"`
+	VerseKey        string   `json:"verse_key"`
+	CharTypeName    string   `json:"char_type_name"`
+	LineNumber      int      `json:"line_number"`
+	PageNumber      int      `json:"page_number"`
+	CodeV1          string   `json:"code_v1"`
+	AudioURL        string   `json:"audio_url"`
+	Translation     Resource `json:"translation"`
+	Transliteration Resource `json:"transliteration"`
+}
+
+func (a apiWordV4) word() Word {
+	w := Word{
+		ID:              a.ID,
+		Position:        a.Position,
+		TextUthmani:     a.TextUthmani,
+		TextImlaei:      a.TextImlaei,
+		TextIndopak:     a.TextIndopak,
+		VerseKey:        a.VerseKey,
+		CharType:        a.CharTypeName,
+		LineNumber:      a.LineNumber,
+		PageNumber:      a.PageNumber,
+		Code:            a.CodeV1,
+		Translation:     a.Translation,
+		Transliteration: a.Transliteration,
+	}
+	if w.TextUthmani == "" {
+		w.TextUthmani = a.Text
+	}
+	w.Audio.URL = a.AudioURL
+	return w
+}
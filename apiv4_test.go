@@ -0,0 +1,216 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// v4Handler returns a handler serving the body for the path, it fails the test on requests
+// to any other path.
+func v4Handler(t *testing.T, path, body string, check func(r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("got request to %s, want %s", r.URL.Path, path)
+			http.NotFound(w, r)
+			return
+		}
+		if check != nil {
+			check(r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func TestClient_v4Chapters(t *testing.T) {
+	const body = `{"chapters": [
+		{"id": 2, "revelation_place": "madinah", "revelation_order": 87, "bismillah_pre": true, "name_simple": "Al-Baqarah", "name_complex": "Al-Baqarah", "name_arabic": "البقرة", "verses_count": 286, "pages": [2, 49], "translated_name": {"language_name": "english", "name": "The Cow"}},
+		{"id": 1, "revelation_place": "makkah", "revelation_order": 5, "bismillah_pre": false, "name_simple": "Al-Fatihah", "name_complex": "Al-Fātiĥah", "name_arabic": "الفاتحة", "verses_count": 7, "pages": [1, 1], "translated_name": {"language_name": "english", "name": "The Opener"}}
+	]}`
+	c := newHandlerClient(t, v4Handler(t, "/api/v4/chapters", body, nil), WithAPIVersion("v4"))
+
+	got, err := c.Chapters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Chapter{
+		{
+			ID:              1,
+			ChapterNumber:   1,
+			RevelationOrder: 5,
+			RevelationPlace: "makkah",
+			NameComplex:     "Al-Fātiĥah",
+			NameArabic:      "الفاتحة",
+			NameSimple:      "Al-Fatihah",
+			VersesCount:     7,
+			Pages:           Pages{Start: 1, End: 1},
+			TranslatedName:  TranslatedName{LanguageName: "english", Name: "The Opener"},
+		},
+		{
+			ID:              2,
+			ChapterNumber:   2,
+			BismillahPre:    true,
+			RevelationOrder: 87,
+			RevelationPlace: "madinah",
+			NameComplex:     "Al-Baqarah",
+			NameArabic:      "البقرة",
+			NameSimple:      "Al-Baqarah",
+			VersesCount:     286,
+			Pages:           Pages{Start: 2, End: 49},
+			TranslatedName:  TranslatedName{LanguageName: "english", Name: "The Cow"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+// v4VersesBody is a v4 page of verses, the first two verses of al-fatihah with their words,
+// a translation and audio.
+const v4VersesBody = `{
+	"verses": [
+		{
+			"id": 1, "verse_number": 1, "verse_key": "1:1", "hizb_number": 1, "rub_el_hizb_number": 1,
+			"juz_number": 1, "page_number": 1, "sajdah_type": null, "sajdah_number": null,
+			"text_uthmani": "بِسْمِ ٱللَّهِ ٱلرَّحْمَٰنِ ٱلرَّحِيمِ",
+			"words": [
+				{"id": 1, "position": 1, "audio_url": "wbw/001_001_001.mp3", "char_type_name": "word", "code_v1": "&#xfb51;", "page_number": 1, "line_number": 2, "text": "بِسْمِ", "verse_key": "1:1", "translation": {"text": "In (the) name", "language_name": "english"}, "transliteration": {"text": "bis'mi", "language_name": "english"}},
+				{"id": 2, "position": 2, "char_type_name": "end", "code_v1": "&#xfb55;", "page_number": 1, "line_number": 2, "text": "١", "verse_key": "1:1"}
+			],
+			"translations": [{"id": 1, "resource_id": 20, "text": "In the name of Allah"}],
+			"audio": {"url": "AbdulBaset/Mujawwad/mp3/001001.mp3", "segments": [[0, 1, 0, 2240]]}
+		},
+		{"id": 2, "verse_number": 2, "verse_key": "1:2", "hizb_number": 1, "rub_el_hizb_number": 1, "juz_number": 1, "page_number": 1}
+	],
+	"pagination": {"per_page": 2, "current_page": 1, "next_page": 2, "total_pages": 4, "total_records": 7}
+}`
+
+func TestClient_v4Verses(t *testing.T) {
+	check := func(r *http.Request) {
+		q := r.URL.Query()
+		want := map[string]string{
+			"per_page":     "2",
+			"page":         "1",
+			"words":        "true",
+			"translations": "20,131",
+			"audio":        "7",
+			"language":     "en",
+		}
+		for k, v := range want {
+			if got := q.Get(k); got != v {
+				t.Errorf("got %s=%q, want %q", k, got, v)
+			}
+		}
+		for _, k := range []string{"limit", "translations[]", "recitation"} {
+			if q.Has(k) {
+				t.Errorf("sent the v3 param %s", k)
+			}
+		}
+		if q.Get("fields") == "" || q.Get("word_fields") == "" {
+			t.Errorf("got fields %q and word fields %q, want the verse text asked for", q.Get("fields"), q.Get("word_fields"))
+		}
+	}
+	c := newHandlerClient(t, v4Handler(t, "/api/v4/verses/by_chapter/1", v4VersesBody, check), WithAPIVersion("v4"))
+
+	page, err := c.VersesPage(context.Background(), 1,
+		VersesLimit(2),
+		VersesPage(1),
+		VersesTranslations([]int{20, 131}),
+		VersesRecitation(7),
+		VersesLanguage("en"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if page.CurrentPage != 1 || page.NextPage != 2 || page.PrevPage != nil || page.TotalPages != 4 || page.TotalCount != 7 {
+		t.Errorf("got pagination %+v", page)
+	}
+	if len(page.Verses) != 2 {
+		t.Fatalf("got %d verses, want 2", len(page.Verses))
+	}
+
+	v := page.Verses[0]
+	if v.ChapterID != 1 || v.VerseKey != "1:1" || v.RubNumber != 1 || v.TextUthmani == "" {
+		t.Errorf("got verse %+v", v)
+	}
+	if len(v.Translations) != 1 || v.Translations[0].ResourceID != 20 || v.Translations[0].Text != "In the name of Allah" {
+		t.Errorf("got translations %+v", v.Translations)
+	}
+	if v.Audio.URL == "" || !reflect.DeepEqual(v.Audio.Segments, [][]string{{"0", "1", "0", "2240"}}) {
+		t.Errorf("got audio %+v", v.Audio)
+	}
+
+	words := v.WordsOnly()
+	if len(words) != 1 {
+		t.Fatalf("got %d words, want 1", len(words))
+	}
+	if w := words[0]; w.TextUthmani != "بِسْمِ" || w.Transliteration.Text != "bis'mi" || w.Translation.Text != "In (the) name" || w.Audio.URL == "" {
+		t.Errorf("got word %+v", w)
+	}
+	if _, ok := v.EndMarker(); !ok {
+		t.Error("got no end marker")
+	}
+}
+
+func TestClient_v4Verse(t *testing.T) {
+	const body = `{"verse": {"id": 262, "verse_number": 255, "verse_key": "2:255", "rub_el_hizb_number": 17}}`
+	c := newHandlerClient(t, v4Handler(t, "/api/v4/verses/by_key/2:255", body, nil), WithAPIVersion("v4"))
+
+	v, err := c.Verse(context.Background(), 2, 255)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ChapterID != 2 || v.VerseNumber != 255 || v.RubNumber != 17 {
+		t.Errorf("got verse %+v", v)
+	}
+}
+
+func TestClient_unsupportedAPIVersion(t *testing.T) {
+	ctx := context.Background()
+
+	var requests int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{}`))
+	})
+	v4 := newHandlerClient(t, h, WithAPIVersion("v4"))
+	unknown := newHandlerClient(t, h, WithAPIVersion("v9"))
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "v4 Recitations", call: func() error { _, err := v4.Recitations(ctx); return err }},
+		{name: "v4 Translations", call: func() error { _, err := v4.Translations(ctx); return err }},
+		{name: "v4 Languages", call: func() error { _, err := v4.Languages(ctx); return err }},
+		{name: "v4 Tafsiraat", call: func() error { _, err := v4.Tafsiraat(ctx); return err }},
+		{name: "v4 VerseTafsir", call: func() error { _, err := v4.VerseTafsir(ctx, 1, 1); return err }},
+		{name: "v4 VerseTranslations", call: func() error {
+			_, err := v4.VerseTranslations(ctx, VerseKey{Chapter: 1, Verse: 1}, nil)
+			return err
+		}},
+		{name: "v4 Search", call: func() error { _, err := v4.Search(ctx, SearchRequest{Query: "mercy"}); return err }},
+		{name: "v4 verses offset", call: func() error { _, err := v4.Verses(ctx, 1, VersesOffset(5)); return err }},
+		{name: "v4 verses media", call: func() error { _, err := v4.Verses(ctx, 1, VersesMedia([]int{1})); return err }},
+		{name: "v4 madani text", call: func() error { _, err := v4.Verses(ctx, 1, VersesTextType(TextTypeMadani)); return err }},
+		{name: "unknown version Chapters", call: func() error { _, err := unknown.Chapters(ctx); return err }},
+		{name: "unknown version Verses", call: func() error { _, err := unknown.Verses(ctx, 1); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, ErrUnsupportedAPIVersion) {
+				t.Errorf("got error %v, want ErrUnsupportedAPIVersion", err)
+			}
+		})
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("sent %d requests, want none", n)
+	}
+}
@@ -0,0 +1,110 @@
+package quranc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// MushafPages is the number of pages in the madani mushaf layout used by quran.com.
+const MushafPages = 604
+
+// AudioSegment is a single timed portion of a verse recitation. The words are the
+// positions within the verse the segment covers, and the start and end are offsets
+// from the beginning of the verse audio.
+type AudioSegment struct {
+	StartWord int
+	EndWord   int
+	Start     time.Duration
+	End       time.Duration
+}
+
+// VerseAudioTiming is the recitation audio of a verse along with its parsed segments.
+type VerseAudioTiming struct {
+	VerseKey string
+	URL      string
+	Duration int
+	Segments []AudioSegment
+}
+
+// PageAudioSegments returns the audio timings of every verse on the given mushaf page
+// for the recitation provided. This is useful for highlighting words while the page
+// is being recited. The page must be between 1 and 604.
+func (c *Client) PageAudioSegments(ctx context.Context, page, recitationID int) ([]VerseAudioTiming, error) {
+	verses, err := c.VersesByPage(ctx, page, VersesRecitation(recitationID))
+	if err != nil {
+		return nil, err
+	}
+
+	timings := make([]VerseAudioTiming, 0, len(verses))
+	for _, v := range verses {
+		timings = append(timings, VerseAudioTiming{
+			VerseKey: v.VerseKey,
+			URL:      v.Audio.URL,
+			Duration: v.Audio.Duration,
+			Segments: parseAudioSegments(v.Audio.Segments),
+		})
+	}
+	return timings, nil
+}
+
+// RecitationsByStyle returns the recitations grouped by their style, i.e. murattal and
+// mujawwad. Recitations without a style are grouped under the empty string.
+func RecitationsByStyle(ctx context.Context, api QuranAPI, reqOpts ...ReqOptFn) (map[string][]Recitation, error) {
+	recitations, err := api.Recitations(ctx, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	styles := make(map[string][]Recitation)
+	for _, r := range recitations {
+		styles[r.Style] = append(styles[r.Style], r)
+	}
+	return styles, nil
+}
+
+// parseAudioSegments converts the raw segments of the api into typed segments. The api
+// sends segments as [start word, end word, start ms, end ms], some recitations leave out
+// the end word and send [word, start ms, end ms]. Malformed segments are skipped.
+func parseAudioSegments(raw [][]string) []AudioSegment {
+	segments := make([]AudioSegment, 0, len(raw))
+	for _, seg := range raw {
+		nums := make([]int, 0, len(seg))
+		for _, s := range seg {
+			i, err := strconv.Atoi(s)
+			if err != nil {
+				break
+			}
+			nums = append(nums, i)
+		}
+		if len(nums) != len(seg) {
+			continue
+		}
+
+		switch len(nums) {
+		case 3:
+			segments = append(segments, AudioSegment{
+				StartWord: nums[0],
+				EndWord:   nums[0],
+				Start:     time.Duration(nums[1]) * time.Millisecond,
+				End:       time.Duration(nums[2]) * time.Millisecond,
+			})
+		case 4:
+			segments = append(segments, AudioSegment{
+				StartWord: nums[0],
+				EndWord:   nums[1],
+				Start:     time.Duration(nums[2]) * time.Millisecond,
+				End:       time.Duration(nums[3]) * time.Millisecond,
+			})
+		}
+	}
+	return segments
+}
+
+func validatePage(page int) error {
+	if page < 1 || page > MushafPages {
+		return fmt.Errorf("invalid page %d: must be between 1 and %d", page, MushafPages)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package quranc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAudioSegments(t *testing.T) {
+	ms := time.Millisecond
+
+	tests := []struct {
+		name string
+		raw  [][]string
+		want []AudioSegment
+	}{
+		{name: "none", want: []AudioSegment{}},
+		{
+			name: "start and end words",
+			raw:  [][]string{{"1", "2", "0", "2240"}, {"3", "3", "2240", "3100"}},
+			want: []AudioSegment{
+				{StartWord: 1, EndWord: 2, Start: 0, End: 2240 * ms},
+				{StartWord: 3, EndWord: 3, Start: 2240 * ms, End: 3100 * ms},
+			},
+		},
+		{
+			name: "single word",
+			raw:  [][]string{{"4", "3100", "4000"}},
+			want: []AudioSegment{{StartWord: 4, EndWord: 4, Start: 3100 * ms, End: 4000 * ms}},
+		},
+		{
+			name: "short rows are skipped",
+			raw:  [][]string{{}, {"1"}, {"1", "0"}, {"1", "1", "0", "500"}},
+			want: []AudioSegment{{StartWord: 1, EndWord: 1, Start: 0, End: 500 * ms}},
+		},
+		{
+			name: "long rows are skipped",
+			raw:  [][]string{{"1", "1", "0", "500", "900"}},
+			want: []AudioSegment{},
+		},
+		{
+			name: "non-numeric rows are skipped",
+			raw:  [][]string{{"1", "x", "0", "500"}, {"1", "0", "500ms"}, {"2", "2", "500", "900"}},
+			want: []AudioSegment{{StartWord: 2, EndWord: 2, Start: 500 * ms, End: 900 * ms}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseAudioSegments(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_PageAudioSegments(t *testing.T) {
+	ctx := context.Background()
+	const body = `{"verses": [
+		{"verse_key": "1:2", "chapter_id": 1, "verse_number": 2, "audio": {"url": "001002.mp3", "duration": 5, "segments": [["1", "2", "0", "1200"], ["3", "1200"]]}},
+		{"verse_key": "1:1", "chapter_id": 1, "verse_number": 1, "audio": {"url": "001001.mp3", "duration": 6, "segments": [["1", "0", "800"]]}},
+		{"verse_key": "1:3", "chapter_id": 1, "verse_number": 3}
+	]}`
+	c := newTestClient(t, body)
+
+	got, err := c.PageAudioSegments(ctx, 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VerseAudioTiming{
+		{VerseKey: "1:1", URL: "001001.mp3", Duration: 6, Segments: []AudioSegment{{StartWord: 1, EndWord: 1, End: 800 * time.Millisecond}}},
+		{VerseKey: "1:2", URL: "001002.mp3", Duration: 5, Segments: []AudioSegment{{StartWord: 1, EndWord: 2, End: 1200 * time.Millisecond}}},
+		{VerseKey: "1:3", Segments: []AudioSegment{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	t.Run("invalid page", func(t *testing.T) {
+		for _, page := range []int{0, MushafPages + 1} {
+			if _, err := c.PageAudioSegments(ctx, page, 7); err == nil {
+				t.Errorf("page %d: got no error", page)
+			}
+		}
+	})
+}
+
+func TestRecitationsByStyle(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		body string
+		want map[string][]int
+	}{
+		{name: "no recitations", body: `{"recitations": []}`, want: map[string][]int{}},
+		{
+			name: "by style",
+			body: `{"recitations": [
+				{"id": 1, "style": "mujawwad"},
+				{"id": 2, "style": "murattal"},
+				{"id": 3, "style": "mujawwad"},
+				{"id": 4, "style": null},
+				{"id": 5}
+			]}`,
+			want: map[string][]int{"mujawwad": {1, 3}, "murattal": {2}, "": {4, 5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			styles, err := RecitationsByStyle(ctx, newTestClient(t, tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string][]int, len(styles))
+			for style, recitations := range styles {
+				for _, r := range recitations {
+					got[style] = append(got[style], r.ID)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("errors are returned", func(t *testing.T) {
+		if _, err := RecitationsByStyle(ctx, New(WithAPIVersion("v4"))); err == nil {
+			t.Error("got no error")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package quranc
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff computes exponentially growing delays between attempts, with optional jitter
+// so that many clients backing off at once do not retry in lock step.
+type Backoff struct {
+	// Base is the delay before the first retry.
+	Base time.Duration
+	// Max caps the delay, zero leaves it uncapped.
+	Max time.Duration
+	// Factor is what the delay is multiplied by each attempt, defaults to 2.
+	Factor float64
+	// Jitter is the fraction, between 0 and 1, of the delay that is randomly taken
+	// off of it. A jitter of 0.2 returns delays between 80% and 100% of the delay.
+	Jitter float64
+
+	// rand is the source of randomness the jitter is drawn from, the client's source set
+	// with WithRandSource. The global math/rand source is used when it is nil.
+	rand *lockedRand
+}
+
+// Next returns the delay before the given attempt, where attempt 0 is the first retry.
+// Delays grow monotonically up to Max before jitter is applied, and jitter never takes
+// a delay above Max.
+func (b Backoff) Next(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	factor := b.Factor
+	if factor < 1 {
+		factor = 2
+	}
+
+	d := float64(b.Base) * math.Pow(factor, float64(attempt))
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	// an uncapped delay grows past what a Duration holds within a few dozen attempts,
+	// converting it would overflow.
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+
+	if jitter := math.Min(b.Jitter, 1); jitter > 0 {
+		d -= d * jitter * b.float64()
+	}
+	if d >= maxBackoff {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// float64 returns a random number in [0.0, 1.0) from the backoff's source of randomness.
+func (b Backoff) float64() float64 {
+	if b.rand == nil {
+		return rand.Float64()
+	}
+	return b.rand.Float64()
+}
+
+// maxBackoff is the longest Duration as a float64. It rounds up to 1<<63, so only delays
+// below it convert to a Duration without overflowing.
+const maxBackoff = float64(math.MaxInt64)
@@ -0,0 +1,135 @@
+package quranc
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackoff_Next(t *testing.T) {
+	t.Run("delays grow monotonically up to max", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			backoff Backoff
+			want    []time.Duration
+		}{
+			{
+				name:    "doubling",
+				backoff: Backoff{Base: time.Second, Max: 10 * time.Second, Factor: 2},
+				want:    []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second},
+			},
+			{
+				name:    "factor defaults to 2",
+				backoff: Backoff{Base: time.Second, Max: 5 * time.Second},
+				want:    []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+			},
+			{
+				name:    "tripling",
+				backoff: Backoff{Base: 100 * time.Millisecond, Max: time.Second, Factor: 3},
+				want:    []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 900 * time.Millisecond, time.Second},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				for attempt, want := range tt.want {
+					if got := tt.backoff.Next(attempt); got != want {
+						t.Errorf("attempt %d: got %s, want %s", attempt, got, want)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("uncapped delays do not overflow", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			backoff Backoff
+		}{
+			{name: "no jitter", backoff: Backoff{Base: time.Second}},
+			{name: "jitter", backoff: Backoff{Base: time.Second, Jitter: 0.5}},
+			{name: "full jitter", backoff: Backoff{Base: time.Second, Jitter: 1}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var prev time.Duration
+				for attempt := 0; attempt < 2000; attempt++ {
+					got := tt.backoff.Next(attempt)
+					if got < 0 {
+						t.Fatalf("attempt %d: got negative delay %s", attempt, got)
+					}
+					if tt.backoff.Jitter == 0 && got < prev {
+						t.Fatalf("attempt %d: got %s, less than the %s before it", attempt, got, prev)
+					}
+					prev = got
+				}
+				if tt.backoff.Jitter == 0 && prev != math.MaxInt64 {
+					t.Errorf("got %s, want the longest duration", prev)
+				}
+			})
+		}
+	})
+
+	t.Run("jitter stays within bounds", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			backoff Backoff
+		}{
+			{name: "a fifth", backoff: Backoff{Base: time.Second, Max: time.Minute, Jitter: 0.2}},
+			{name: "a half", backoff: Backoff{Base: time.Second, Max: time.Minute, Jitter: 0.5}},
+			{name: "above 1 is full", backoff: Backoff{Base: time.Second, Max: time.Minute, Jitter: 3}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				nominal := tt.backoff
+				nominal.Jitter = 0
+				jitter := math.Min(tt.backoff.Jitter, 1)
+
+				for attempt := 0; attempt < 10; attempt++ {
+					max := nominal.Next(attempt)
+					min := time.Duration(float64(max) * (1 - jitter))
+					for i := 0; i < 100; i++ {
+						if got := tt.backoff.Next(attempt); got < min || got > max {
+							t.Fatalf("attempt %d: got %s, want between %s and %s", attempt, got, min, max)
+						}
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("jitter is drawn from the source", func(t *testing.T) {
+		// delays returns the delays of the first attempts of a backoff drawing its jitter
+		// from a source seeded with the seed.
+		delays := func(seed int64) []time.Duration {
+			b := Backoff{Base: time.Second, Max: time.Minute, Jitter: 0.5, rand: newLockedRand(rand.NewSource(seed), nil)}
+			var got []time.Duration
+			for attempt := 0; attempt < 5; attempt++ {
+				got = append(got, b.Next(attempt))
+			}
+			return got
+		}
+
+		tests := []struct {
+			name     string
+			seeds    [2]int64
+			wantSame bool
+		}{
+			{name: "same seed", seeds: [2]int64{1, 1}, wantSame: true},
+			{name: "other seed", seeds: [2]int64{1, 2}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				first, second := delays(tt.seeds[0]), delays(tt.seeds[1])
+				if reflect.DeepEqual(first, second) != tt.wantSame {
+					t.Errorf("got delays %v and %v, want the same %t", first, second, tt.wantSame)
+				}
+			})
+		}
+	})
+}
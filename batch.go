@@ -0,0 +1,100 @@
+package quranc
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// concurrencyDefaulter is implemented by the QuranAPIs that carry the default concurrency
+// of batch helpers, set on the client with WithDefaultConcurrency. The middlewares forward
+// it from the QuranAPI they wrap.
+type concurrencyDefaulter interface {
+	defaultConcurrency() int
+}
+
+// apiConcurrency returns the concurrency a batch helper calls the api with. A concurrency
+// of zero uses the default of the api, or GOMAXPROCS when the api has none.
+func apiConcurrency(api QuranAPI, concurrency int) int {
+	if concurrency > 0 {
+		return concurrency
+	}
+	if d, ok := api.(concurrencyDefaulter); ok {
+		return d.defaultConcurrency()
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// batch calls fn for every index in [0, n) with at most concurrency calls in flight. Once
+// the context is done no new calls are started. Progress is reported to the ProgressFunc,
+// when provided, after every call that succeeds, with the chapter returned from chapterOf,
+// when provided. The last progress is reported before batch returns. The
+// progress made is returned along with the errors of the calls that fail as a MultiError.
+// When the context ends the batch early its error is returned alone, the calls it cut
+// short fail because of it, so a deadline can be told apart from failed calls.
+func batch(ctx context.Context, n, concurrency int, progressFn ProgressFunc, chapterOf func(i int) int, fn func(ctx context.Context, i int) error) (Progress, error) {
+	if concurrency < 1 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	var (
+		mu       sync.Mutex
+		progress = Progress{Total: n}
+		errs     MultiError
+		reporter = newProgressReporter(progressFn)
+	)
+	defer reporter.close()
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				if ctx.Err() != nil {
+					continue
+				}
+				err := fn(ctx, i)
+
+				mu.Lock()
+				switch {
+				case err != nil && ctx.Err() == nil:
+					errs = append(errs, err)
+				case err != nil:
+					// the call was cut short by the context, its error is returned in
+					// place of the call's.
+				default:
+					progress.Done++
+					if chapterOf != nil {
+						progress.CurrentChapter = chapterOf(i)
+					}
+					// updated under the lock to keep updates in order, the
+					// reporter calls the ProgressFunc from a goroutine of its own.
+					reporter.update(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case work <- i:
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if progress.Done+len(errs) < n {
+		// only the context ends the batch before every call is made.
+		return progress, ctx.Err()
+	}
+	if len(errs) > 0 {
+		return progress, errs
+	}
+	return progress, nil
+}
@@ -0,0 +1,73 @@
+package quranc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatch_progress(t *testing.T) {
+	t.Run("updates arrive in order up to completion", func(t *testing.T) {
+		var updates []Progress
+		progressFn := func(p Progress) { updates = append(updates, p) }
+
+		progress, err := batch(context.Background(), 20, 4, progressFn, func(i int) int { return i + 1 }, func(ctx context.Context, i int) error {
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if progress.Done != 20 || progress.Total != 20 {
+			t.Fatalf("got progress %+v, want 20 of 20 done", progress)
+		}
+
+		if len(updates) == 0 {
+			t.Fatal("got no progress updates")
+		}
+		for i, p := range updates {
+			if p.Total != 20 {
+				t.Errorf("update %d has a total of %d, want 20", i, p.Total)
+			}
+			if i > 0 && p.Done <= updates[i-1].Done {
+				t.Errorf("update %d went from %d to %d done", i, updates[i-1].Done, p.Done)
+			}
+		}
+		if last := updates[len(updates)-1]; last.Done != 20 {
+			t.Errorf("got a last update of %d done, want 20", last.Done)
+		}
+	})
+
+	t.Run("a blocked callback does not hold up the workers", func(t *testing.T) {
+		const n = 5
+		var (
+			mu        sync.Mutex
+			calls     int
+			allCalled = make(chan struct{})
+			last      Progress
+		)
+		progressFn := func(p Progress) {
+			select {
+			case <-allCalled:
+			case <-time.After(5 * time.Second):
+				t.Error("the workers waited on the progress callback")
+			}
+			last = p
+		}
+
+		_, err := batch(context.Background(), n, 1, progressFn, nil, func(ctx context.Context, i int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if calls++; calls == n {
+				close(allCalled)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last.Done != n {
+			t.Errorf("got a last update of %d done, want %d", last.Done, n)
+		}
+	})
+}
@@ -4,101 +4,262 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"go.etcd.io/bbolt"
 )
 
+// StaleAware is implemented by caches that can serve expired entries while they are
+// refreshed in the background, i.e. the QuranAPI returned from BoltCache with a ttl set.
+// This lets a UI render cached data instantly and mark it as stale, callers type assert
+// the QuranAPI for it.
+type StaleAware interface {
+	ChaptersStale(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter, bool, error)
+}
+
 type boltCacheMiddleware struct {
 	db   *bbolt.DB
 	next QuranAPI
+
+	ttl            time.Duration
+	refreshAhead   bool
+	refreshWindow  time.Duration
+	refreshTimeout time.Duration
+
+	refreshBackoff Backoff
+
+	onCorrupt func(loc cacheLocation, err error)
+	now       func() time.Time
+
+	counters cacheCounters
+
+	mu              sync.Mutex
+	refreshing      map[string]bool
+	refreshFailures map[string]refreshFailure
+}
+
+// refreshFailure tracks the failed background refreshes of a location, so they can be
+// backed off from rather than retried on every hit.
+type refreshFailure struct {
+	attempts int
+	retryAt  time.Time
 }
 
 const (
 	bucketChapters     = "chapters"
 	bucketChapter      = "chapter"
 	bucketChapterInfo  = "chapterinfo"
+	bucketInfoLangs    = "chapterinfo_languages"
+	bucketWordCount    = "word_count"
 	bucketJuzzah       = "juzzah"
 	bucketLanguages    = "languages"
 	bucketRecitations  = "recitations"
+	bucketSearch       = "search"
 	bucketTafsiraat    = "tafsiraat"
 	bucketTranslations = "translations"
 	bucketVerse        = "verse"
 	bucketVerseTafsir  = "verse_tafsir"
+	bucketVerseTrans   = "verse_translations"
 	bucketVerses       = "verses"
 )
 
-func BoltCache(client QuranAPI, db *bbolt.DB) (QuranAPI, error) {
-	buckets := map[string][]string{
-		bucketChapters:     {bucketChapter, bucketChapterInfo},
-		bucketJuzzah:       nil,
-		bucketLanguages:    nil,
-		bucketRecitations:  nil,
-		bucketTafsiraat:    nil,
-		bucketTranslations: nil,
-		bucketVerses:       {bucketVerse, bucketVerseTafsir},
-	}
-	for bucket, nestedBuckets := range buckets {
-		err := db.Update(func(tx *bbolt.Tx) error {
-			b, err := tx.CreateBucketIfNotExists([]byte(bucket))
-			if err != nil {
-				return fmt.Errorf("create bucket %q: %s", bucket, err)
-			}
+// cacheBuckets are the top level buckets of the cache and the buckets nested in them.
+var cacheBuckets = map[string][]string{
+	bucketChapters:     {bucketChapter, bucketChapterInfo, bucketInfoLangs, bucketWordCount},
+	bucketJuzzah:       nil,
+	bucketLanguages:    nil,
+	bucketRecitations:  nil,
+	bucketSearch:       nil,
+	bucketTafsiraat:    nil,
+	bucketTranslations: nil,
+	bucketVerses:       {bucketVerse, bucketVerseTafsir, bucketVerseTrans},
+}
 
-			for _, nestedBucket := range nestedBuckets {
-				_, err := b.CreateBucketIfNotExists([]byte(nestedBucket))
-				if err != nil {
-					return fmt.Errorf("create nested bucket %q: %s", nestedBucket, err)
-				}
-			}
-			return nil
+// createBucket creates the top level bucket and the buckets nested in it, when they do
+// not exist.
+func createBucket(tx *bbolt.Tx, bucket string) error {
+	b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return fmt.Errorf("create bucket %q: %s", bucket, err)
+	}
+
+	for _, nestedBucket := range cacheBuckets[bucket] {
+		_, err := b.CreateBucketIfNotExists([]byte(nestedBucket))
+		if err != nil {
+			return fmt.Errorf("create nested bucket %q: %s", nestedBucket, err)
+		}
+	}
+	return nil
+}
+
+type (
+	// BoltCacheOptFn is an option to set the options of the bolt cache constructor.
+	BoltCacheOptFn func(opt boltCacheOpt) boltCacheOpt
+
+	boltCacheOpt struct {
+		ttl            time.Duration
+		refreshAhead   bool
+		refreshWindow  time.Duration
+		refreshTimeout time.Duration
+		refreshBackoff Backoff
+		onCorrupt      func(bucket string, key []byte, err error)
+		now            func() time.Time
+	}
+)
+
+// BoltCacheTTL sets how long a cached entry is served before it is considered expired
+// and refetched. The default of zero never expires entries.
+func BoltCacheTTL(ttl time.Duration) BoltCacheOptFn {
+	return func(opt boltCacheOpt) boltCacheOpt {
+		opt.ttl = ttl
+		return opt
+	}
+}
+
+// WithRefreshAhead enables refreshing entries that are close to expiring. A hit within
+// the refresh window of expiry returns the cached value immediately and refreshes the
+// entry in the background, so hot data never has to wait on a miss. It requires a ttl
+// to be set.
+func WithRefreshAhead(enabled bool) BoltCacheOptFn {
+	return func(opt boltCacheOpt) boltCacheOpt {
+		opt.refreshAhead = enabled
+		return opt
+	}
+}
+
+// RefreshAheadWindow sets how close to expiry an entry must be for a hit to trigger
+// a background refresh. The default is a tenth of the ttl.
+func RefreshAheadWindow(window time.Duration) BoltCacheOptFn {
+	return func(opt boltCacheOpt) boltCacheOpt {
+		opt.refreshWindow = window
+		return opt
+	}
+}
+
+// RefreshAheadTimeout bounds how long a single background refresh may take. The
+// default is 30s.
+func RefreshAheadTimeout(timeout time.Duration) BoltCacheOptFn {
+	return func(opt boltCacheOpt) boltCacheOpt {
+		opt.refreshTimeout = timeout
+		return opt
+	}
+}
+
+// RefreshAheadBackoff sets the backoff between background refreshes of an entry after
+// a refresh fails. The default starts at 1s and grows up to 1m.
+func RefreshAheadBackoff(backoff Backoff) BoltCacheOptFn {
+	return func(opt boltCacheOpt) boltCacheOpt {
+		opt.refreshBackoff = backoff
+		return opt
+	}
+}
+
+// BoltCacheOnCorrupt sets a function that is called with the location of any entry that
+// exists in the cache but fails to decode, i.e. after the cached type changed. The bucket
+// is the path to the entry's bucket joined by "/". Corrupt entries are always removed and
+// refetched, this is useful for logging them.
+func BoltCacheOnCorrupt(fn func(bucket string, key []byte, err error)) BoltCacheOptFn {
+	return func(opt boltCacheOpt) boltCacheOpt {
+		opt.onCorrupt = fn
+		return opt
+	}
+}
+
+// WithClock sets the function the cache reads the current time from, to expire and
+// refresh entries. The default is time.Now, it is useful for tests to control time.
+func WithClock(now func() time.Time) BoltCacheOptFn {
+	return func(opt boltCacheOpt) boltCacheOpt {
+		opt.now = now
+		return opt
+	}
+}
+
+func BoltCache(client QuranAPI, db *bbolt.DB, opts ...BoltCacheOptFn) (QuranAPI, error) {
+	opt := boltCacheOpt{
+		refreshTimeout: 30 * time.Second,
+		refreshBackoff: Backoff{
+			Base:   time.Second,
+			Max:    time.Minute,
+			Factor: 2,
+			Jitter: 0.2,
+		},
+	}
+	for _, o := range opts {
+		opt = o(opt)
+	}
+	if opt.now == nil {
+		opt.now = time.Now
+	}
+	if opt.refreshWindow <= 0 {
+		opt.refreshWindow = opt.ttl / 10
+	}
+
+	for bucket := range cacheBuckets {
+		err := db.Update(func(tx *bbolt.Tx) error {
+			return createBucket(tx, bucket)
 		})
 		if err != nil {
 			return nil, err
 		}
 	}
 	return &boltCacheMiddleware{
-		db:   db,
-		next: client,
+		db:              db,
+		next:            client,
+		ttl:             opt.ttl,
+		refreshAhead:    opt.refreshAhead && opt.ttl > 0,
+		refreshWindow:   opt.refreshWindow,
+		refreshTimeout:  opt.refreshTimeout,
+		refreshBackoff:  opt.refreshBackoff,
+		refreshing:      make(map[string]bool),
+		refreshFailures: make(map[string]refreshFailure),
+		now:             opt.now,
+		onCorrupt: func(loc cacheLocation, err error) {
+			if opt.onCorrupt != nil {
+				opt.onCorrupt(strings.Join(loc.buckets, "/"), loc.key, err)
+			}
+		},
 	}, nil
 }
 
+// Stats returns the hits, misses and errors of every method called through the cache.
+func (bc *boltCacheMiddleware) Stats() CacheStats {
+	return bc.counters.stats()
+}
+
+func (bc *boltCacheMiddleware) defaultConcurrency() int {
+	return apiConcurrency(bc.next, 0)
+}
+
 func (bc *boltCacheMiddleware) Recitations(ctx context.Context, reqOpts ...ReqOptFn) ([]Recitation, error) {
 	var opt reqOpt
 	for _, o := range reqOpts {
 		opt = o(opt)
 	}
 
-	bucket := []byte(bucketRecitations)
-	cacheID := []byte(itoa(opt.languageID))
+	cacheID, err := opt.key()
+	if err != nil {
+		return bc.next.Recitations(ctx, reqOpts...)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketRecitations},
+		key:     cacheID,
+	}
 
 	var out []Recitation
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
-		return valueDecode(b.Get(cacheID), &out)
+	err = bc.cacheAside(ctx, "Recitations", loc, opt.noCache, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Recitations(ctx, reqOpts...)
 	})
-	if err == nil {
-		return out, nil
-	}
-
-	clientOut, err := bc.next.Recitations(ctx, reqOpts...)
 	if err != nil {
 		return nil, err
 	}
-
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket)
-		return b.Put(cacheID, buf.Bytes())
-	})
-
-	return clientOut, nil
+	return out, nil
 }
 
 func (bc *boltCacheMiddleware) Translations(ctx context.Context, reqOpts ...ReqOptFn) ([]Translation, error) {
@@ -107,34 +268,23 @@ func (bc *boltCacheMiddleware) Translations(ctx context.Context, reqOpts ...ReqO
 		opt = o(opt)
 	}
 
-	bucket := []byte(bucketTranslations)
-	cacheID := []byte(itoa(opt.languageID))
+	cacheID, err := opt.key()
+	if err != nil {
+		return bc.next.Translations(ctx, reqOpts...)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketTranslations},
+		key:     cacheID,
+	}
 
 	var out []Translation
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
-		return valueDecode(b.Get(cacheID), &out)
+	err = bc.cacheAside(ctx, "Translations", loc, opt.noCache, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Translations(ctx, reqOpts...)
 	})
-	if err == nil {
-		return out, nil
-	}
-
-	clientOut, err := bc.next.Translations(ctx, reqOpts...)
 	if err != nil {
 		return nil, err
 	}
-
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket)
-		return b.Put(cacheID, buf.Bytes())
-	})
-
-	return clientOut, nil
+	return out, nil
 }
 
 func (bc *boltCacheMiddleware) Languages(ctx context.Context, reqOpts ...ReqOptFn) ([]Language, error) {
@@ -143,34 +293,23 @@ func (bc *boltCacheMiddleware) Languages(ctx context.Context, reqOpts ...ReqOptF
 		opt = o(opt)
 	}
 
-	bucket := []byte(bucketLanguages)
-	cacheID := []byte(itoa(opt.languageID))
+	cacheID, err := opt.key()
+	if err != nil {
+		return bc.next.Languages(ctx, reqOpts...)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketLanguages},
+		key:     cacheID,
+	}
 
 	var out []Language
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
-		return valueDecode(b.Get(cacheID), &out)
+	err = bc.cacheAside(ctx, "Languages", loc, opt.noCache, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Languages(ctx, reqOpts...)
 	})
-	if err == nil {
-		return out, nil
-	}
-
-	clientOut, err := bc.next.Languages(ctx, reqOpts...)
 	if err != nil {
 		return nil, err
 	}
-
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket)
-		return b.Put(cacheID, buf.Bytes())
-	})
-
-	return clientOut, nil
+	return out, nil
 }
 
 func (bc *boltCacheMiddleware) Tafsiraat(ctx context.Context, reqOpts ...ReqOptFn) ([]Tafsir, error) {
@@ -179,71 +318,61 @@ func (bc *boltCacheMiddleware) Tafsiraat(ctx context.Context, reqOpts ...ReqOptF
 		opt = o(opt)
 	}
 
-	bucket := []byte(bucketTafsiraat)
-	cacheID := []byte(itoa(opt.languageID))
+	cacheID, err := opt.key()
+	if err != nil {
+		return bc.next.Tafsiraat(ctx, reqOpts...)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketTafsiraat},
+		key:     cacheID,
+	}
 
 	var out []Tafsir
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
-		return valueDecode(b.Get(cacheID), &out)
+	err = bc.cacheAside(ctx, "Tafsiraat", loc, opt.noCache, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Tafsiraat(ctx, reqOpts...)
 	})
-	if err == nil {
-		return out, nil
-	}
-
-	clientOut, err := bc.next.Tafsiraat(ctx, reqOpts...)
 	if err != nil {
 		return nil, err
 	}
+	return out, nil
+}
 
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-
-		b := tx.Bucket(bucket)
-		return b.Put(cacheID, buf.Bytes())
-	})
+func (bc *boltCacheMiddleware) Chapters(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter, error) {
+	chapters, _, err := bc.chapters(ctx, false, reqOpts...)
+	return chapters, err
+}
 
-	return clientOut, nil
+// ChaptersStale returns the chapters as Chapters does, serving an expired entry rather
+// than waiting on a refetch. The entry is refreshed in the background and reported as
+// stale.
+func (bc *boltCacheMiddleware) ChaptersStale(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter, bool, error) {
+	return bc.chapters(ctx, true, reqOpts...)
 }
 
-func (bc *boltCacheMiddleware) Chapters(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter, error) {
+func (bc *boltCacheMiddleware) chapters(ctx context.Context, serveStale bool, reqOpts ...ReqOptFn) ([]Chapter, bool, error) {
 	var opt reqOpt
 	for _, o := range reqOpts {
 		opt = o(opt)
 	}
 
-	bucket := []byte(bucketChapters)
-	cacheID := []byte(itoa(opt.languageID))
+	cacheID, err := opt.key()
+	if err != nil {
+		chapters, err := bc.next.Chapters(ctx, reqOpts...)
+		return chapters, false, err
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketChapters},
+		key:     cacheID,
+	}
 
 	var out []Chapter
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
-		return valueDecode(b.Get(cacheID), &out)
+	stale, err := bc.readThrough(ctx, "Chapters", loc, opt.noCache, serveStale, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Chapters(ctx, reqOpts...)
 	})
-	if err == nil {
-		return out, nil
-	}
-
-	clientOut, err := bc.next.Chapters(ctx, reqOpts...)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket)
-		return b.Put(cacheID, buf.Bytes())
-	})
-
-	return clientOut, nil
+	return out, stale, nil
 }
 
 func (bc *boltCacheMiddleware) Chapter(ctx context.Context, id int, reqOpts ...ReqOptFn) (Chapter, error) {
@@ -252,35 +381,23 @@ func (bc *boltCacheMiddleware) Chapter(ctx context.Context, id int, reqOpts ...R
 		opt = o(opt)
 	}
 
-	bucket := []byte(bucketChapters)
-	nestedBucket := []byte(bucketChapter)
-	cacheID := []byte(join(itoa(opt.languageID), itoa(id)))
+	cacheID, err := opt.key(id)
+	if err != nil {
+		return bc.next.Chapter(ctx, id, reqOpts...)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketChapters, bucketChapter},
+		key:     cacheID,
+	}
 
 	var out Chapter
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket).Bucket(nestedBucket)
-		return valueDecode(b.Get(cacheID), &out)
+	err = bc.cacheAside(ctx, "Chapter", loc, opt.noCache, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Chapter(ctx, id, reqOpts...)
 	})
-	if err == nil {
-		return out, nil
-	}
-
-	clientOut, err := bc.next.Chapter(ctx, id, reqOpts...)
 	if err != nil {
 		return Chapter{}, err
 	}
-
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket).Bucket(nestedBucket)
-		return b.Put(cacheID, buf.Bytes())
-	})
-
-	return clientOut, nil
+	return out, nil
 }
 
 func (bc *boltCacheMiddleware) ChapterInfo(ctx context.Context, id int, reqOpts ...ReqOptFn) (ChapterInfo, error) {
@@ -289,35 +406,55 @@ func (bc *boltCacheMiddleware) ChapterInfo(ctx context.Context, id int, reqOpts
 		opt = o(opt)
 	}
 
-	bucket := []byte(bucketChapters)
-	nestedBucket := []byte(bucketChapterInfo)
-	cacheID := []byte(join(itoa(opt.languageID), itoa(id)))
+	cacheID, err := opt.key(id)
+	if err != nil {
+		return bc.next.ChapterInfo(ctx, id, reqOpts...)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketChapters, bucketChapterInfo},
+		key:     cacheID,
+	}
 
 	var out ChapterInfo
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket).Bucket(nestedBucket)
-		return valueDecode(b.Get(cacheID), &out)
+	err = bc.cacheAside(ctx, "ChapterInfo", loc, opt.noCache, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.ChapterInfo(ctx, id, reqOpts...)
 	})
-	if err == nil {
-		return out, nil
-	}
-
-	clientOut, err := bc.next.ChapterInfo(ctx, id, reqOpts...)
 	if err != nil {
 		return ChapterInfo{}, err
 	}
+	return out, nil
+}
 
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket).Bucket(nestedBucket)
-		return b.Put(cacheID, buf.Bytes())
+func (bc *boltCacheMiddleware) ChapterInfoLanguages(ctx context.Context, chapterID int) ([]string, error) {
+	loc := cacheLocation{
+		buckets: []string{bucketChapters, bucketInfoLangs},
+		key:     []byte(itoa(chapterID)),
+	}
+
+	var out []string
+	err := bc.cacheAside(ctx, "ChapterInfoLanguages", loc, false, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.ChapterInfoLanguages(ctx, chapterID)
 	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (bc *boltCacheMiddleware) ChapterWordCount(ctx context.Context, chapterID int) (int, error) {
+	loc := cacheLocation{
+		buckets: []string{bucketChapters, bucketWordCount},
+		key:     []byte(itoa(chapterID)),
+	}
 
-	return clientOut, nil
+	var out int
+	err := bc.cacheAside(ctx, "ChapterWordCount", loc, false, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.ChapterWordCount(ctx, chapterID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return out, nil
 }
 
 func (bc *boltCacheMiddleware) Verses(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
@@ -326,141 +463,345 @@ func (bc *boltCacheMiddleware) Verses(ctx context.Context, chapterID int, reqOpt
 		opt = o(opt)
 	}
 
-	bucket := []byte(bucketVerses)
 	cacheID, err := opt.key(chapterID)
 	if err != nil {
 		return bc.next.Verses(ctx, chapterID, reqOpts...)
 	}
+	loc := cacheLocation{
+		buckets: []string{bucketVerses},
+		key:     cacheID,
+	}
 
 	var out []Verse
-	err = bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
-		return valueDecode(b.Get(cacheID), &out)
+	err = bc.cacheAside(ctx, "Verses", loc, opt.noCache, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Verses(ctx, chapterID, reqOpts...)
 	})
-	if err == nil {
-		return out, nil
+	if err != nil {
+		return nil, err
 	}
+	return out, nil
+}
 
-	clientOut, err := bc.next.Verses(ctx, chapterID, reqOpts...)
+func (bc *boltCacheMiddleware) Verse(ctx context.Context, chapterID, verseID int, reqOpts ...VersesReqOptFn) (Verse, error) {
+	var opt versesReqOpt
+	for _, o := range reqOpts {
+		opt = o(opt)
+	}
+
+	// the key is built from the options even when none are provided, so options that
+	// leave the key unchanged, i.e. NoCache, read and write the same entry as no options.
+	optsKey, err := opt.key(chapterID)
 	if err != nil {
-		return nil, err
+		return bc.next.Verse(ctx, chapterID, verseID, reqOpts...)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketVerses, bucketVerse},
+		key:     append([]byte(join(itoa(chapterID), itoa(verseID))+":"), optsKey...),
 	}
 
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket)
-		return b.Put(cacheID, buf.Bytes())
+	var out Verse
+	err = bc.cacheAside(ctx, "Verse", loc, opt.noCache, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Verse(ctx, chapterID, verseID, reqOpts...)
 	})
-
-	return clientOut, nil
+	if err != nil {
+		return Verse{}, err
+	}
+	return out, nil
 }
 
-func (bc *boltCacheMiddleware) Verse(ctx context.Context, chapterID, verseID int) (Verse, error) {
-	bucket := []byte(bucketVerses)
-	nestedBucket := []byte(bucketVerse)
-	cacheID := []byte(join(itoa(chapterID), itoa(verseID)))
+func (bc *boltCacheMiddleware) Juzzah(ctx context.Context) ([]Juz, error) {
+	loc := cacheLocation{
+		buckets: []string{bucketJuzzah},
+		key:     []byte("juzzah"),
+	}
 
-	var out Verse
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket).Bucket(nestedBucket)
-		return valueDecode(b.Get(cacheID), &out)
+	var out []Juz
+	err := bc.cacheAside(ctx, "Juzzah", loc, false, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Juzzah(ctx)
 	})
-	if err == nil {
-		return out, nil
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (bc *boltCacheMiddleware) VerseTafsir(ctx context.Context, chapterID, verseID int, reqOpts ...VerseTafsirReqOptFn) ([]VerseTafsir, error) {
+	var opt verseTafsirReqOpts
+	for _, o := range reqOpts {
+		opt = o(opt)
 	}
 
-	clientOut, err := bc.next.Verse(ctx, chapterID, verseID)
+	cacheID, err := opt.key(chapterID, verseID)
 	if err != nil {
-		return Verse{}, err
+		return bc.next.VerseTafsir(ctx, chapterID, verseID, reqOpts...)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketVerses, bucketVerseTafsir},
+		key:     cacheID,
 	}
 
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket).Bucket(nestedBucket)
-		return b.Put(cacheID, buf.Bytes())
+	var out []VerseTafsir
+	err = bc.cacheAside(ctx, "VerseTafsir", loc, false, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.VerseTafsir(ctx, chapterID, verseID, reqOpts...)
 	})
-
-	return clientOut, nil
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (bc *boltCacheMiddleware) Juzzah(ctx context.Context) ([]Juz, error) {
-	bucket := []byte(bucketJuzzah)
-	cacheID := []byte("juzzah")
+func (bc *boltCacheMiddleware) VerseTranslations(ctx context.Context, key VerseKey, translationIDs []int) ([]Resource, error) {
+	ids := append([]int(nil), translationIDs...)
+	sort.Ints(ids)
 
-	var out []Juz
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
-		return valueDecode(b.Get(cacheID), &out)
-	})
-	if err == nil {
-		return out, nil
+	cacheID, err := optionsCacheKey(ids, key.Chapter, key.Verse)
+	if err != nil {
+		return bc.next.VerseTranslations(ctx, key, translationIDs)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketVerses, bucketVerseTrans},
+		key:     cacheID,
 	}
 
-	clientOut, err := bc.next.Juzzah(ctx)
+	var out []Resource
+	err = bc.cacheAside(ctx, "VerseTranslations", loc, false, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.VerseTranslations(ctx, key, translationIDs)
+	})
 	if err != nil {
 		return nil, err
 	}
+	return out, nil
+}
 
-	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
-	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
-		if err != nil {
-			return err
-		}
-		b := tx.Bucket(bucket)
-		return b.Put(cacheID, buf.Bytes())
+func (bc *boltCacheMiddleware) Search(ctx context.Context, query SearchRequest) (SearchResponse, error) {
+	if query.Query == "" {
+		// an empty query always errors, there is nothing to cache.
+		return bc.next.Search(ctx, query)
+	}
+
+	cacheID, err := optionsCacheKey(query)
+	if err != nil {
+		return bc.next.Search(ctx, query)
+	}
+	loc := cacheLocation{
+		buckets: []string{bucketSearch},
+		key:     cacheID,
+	}
+
+	var out SearchResponse
+	err = bc.cacheAside(ctx, "Search", loc, false, &out, func(ctx context.Context) (interface{}, error) {
+		return bc.next.Search(ctx, query)
 	})
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	// gob decodes the empty words and translations of a result to nil.
+	out.normalize()
+	return out, nil
+}
 
-	return clientOut, nil
+// cacheLocation is where a value lives in the cache. The buckets are the path from the
+// top level bucket down through any nested buckets to the bucket holding the key.
+type cacheLocation struct {
+	buckets []string
+	key     []byte
 }
 
-func (bc *boltCacheMiddleware) VerseTafsir(ctx context.Context, chapterID, verseID int, reqOpts ...VerseTafsirReqOptFn) ([]VerseTafsir, error) {
-	var opt verseTafsirReqOpts
-	for _, o := range reqOpts {
-		opt = o(opt)
+func (l cacheLocation) bucket(tx *bbolt.Tx) *bbolt.Bucket {
+	b := tx.Bucket([]byte(l.buckets[0]))
+	for _, nested := range l.buckets[1:] {
+		b = b.Bucket([]byte(nested))
 	}
+	return b
+}
 
-	bucket := []byte(bucketVerses)
-	nestedBucket := []byte(bucketVerseTafsir)
-	cacheID := []byte(join(opt.Tafsir, itoa(chapterID), itoa(verseID)))
+func (l cacheLocation) String() string {
+	return strings.Join(l.buckets, "/") + "/" + string(l.key)
+}
 
-	var out []VerseTafsir
-	err := bc.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket).Bucket(nestedBucket)
-		return valueDecode(b.Get(cacheID), &out)
-	})
+// cacheSchemaVersion is the version of the cached types. It is bumped when fields are
+// added to a cached type, or change type, entries of an older version are missing the
+// new fields or do not decode, so they are treated as a miss and refetched.
+const cacheSchemaVersion = 3
+
+// cacheEntry is the value stored in the cache. The value is stored encoded alongside
+// the time it was stored at so that entries may expire, and the schema version it was
+// stored with.
+type cacheEntry struct {
+	StoredAt time.Time
+	Value    []byte
+	Version  int
+}
+
+// fetchFn fetches a value from the next QuranAPI on a cache miss.
+type fetchFn func(ctx context.Context) (interface{}, error)
+
+// cacheAside reads the value at the location into out. On a miss, or when the entry
+// has expired, the value is fetched, written to the cache and set on out. Out must be
+// a pointer to the type returned from the fetch. The method is the name of the api
+// method being cached, used to report stats. When noCache is set the cached value is
+// never read, the value is always fetched and written.
+func (bc *boltCacheMiddleware) cacheAside(ctx context.Context, method string, loc cacheLocation, noCache bool, out interface{}, fetch fetchFn) error {
+	_, err := bc.readThrough(ctx, method, loc, noCache, false, out, fetch)
+	return err
+}
+
+// readThrough implements cacheAside. When serveStale is set an expired entry is set on
+// out rather than refetched, it is refreshed in the background and reported as stale.
+func (bc *boltCacheMiddleware) readThrough(ctx context.Context, method string, loc cacheLocation, noCache, serveStale bool, out interface{}, fetch fetchFn) (stale bool, err error) {
+	stats := callStatsFromContext(ctx)
+
+	var entry cacheEntry
+	err = errCacheMiss
+	if !noCache {
+		entry, err = bc.get(loc)
+	}
+	if err == errLegacyEntry {
+		// entries written before values were stored alongside the time they were stored
+		// at hold the bare value. Those are refetched like an expired entry rather than
+		// reported as corrupt.
+		if valueDecode(entry.Value, out) == nil {
+			err = errCacheMiss
+		}
+	}
 	if err == nil {
-		return out, nil
+		err = valueDecode(entry.Value, out)
+	}
+	if rv := reflect.ValueOf(out).Elem(); err == nil && rv.Kind() == reflect.Slice && rv.IsNil() {
+		// gob decodes an empty slice to nil, list methods always return a non-nil
+		// slice on success.
+		rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+	}
+	if err != nil && err != errCacheMiss {
+		// the entry exists but can not be decoded, i.e. its type changed since it
+		// was written. It is removed so a failed refetch never leaves it behind.
+		bc.onCorrupt(loc, err)
+		bc.delete(loc)
+	}
+	if err == nil {
+		age := bc.now().Sub(entry.StoredAt)
+		expired := bc.expired(age)
+		if !expired || serveStale {
+			bc.counters.hit(method)
+			if stats != nil {
+				stats.recordCache(method, true)
+			}
+			if expired || bc.refreshAhead && age >= bc.ttl-bc.refreshWindow {
+				bc.refresh(loc, fetch)
+			}
+			return expired, nil
+		}
+	}
+	bc.counters.miss(method)
+	if stats != nil {
+		stats.recordCache(method, false)
 	}
 
-	clientOut, err := bc.next.VerseTafsir(ctx, chapterID, verseID, reqOpts...)
+	v, err := fetch(ctx)
 	if err != nil {
-		return nil, err
+		bc.counters.error(method)
+		return false, err
+	}
+	bc.put(loc, v)
+
+	reflect.ValueOf(out).Elem().Set(reflect.ValueOf(v))
+	return false, nil
+}
+
+// refresh fetches the value at the location in the background and writes it to
+// the cache. Only a single refresh per location is ever in flight, and refreshes
+// of a location that failed are backed off from.
+func (bc *boltCacheMiddleware) refresh(loc cacheLocation, fetch fetchFn) {
+	id := loc.String()
+
+	bc.mu.Lock()
+	if bc.refreshing[id] || bc.now().Before(bc.refreshFailures[id].retryAt) {
+		bc.mu.Unlock()
+		return
 	}
+	bc.refreshing[id] = true
+	bc.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), bc.refreshTimeout)
+		defer cancel()
+
+		v, err := fetch(ctx)
+		if err == nil {
+			bc.put(loc, v)
+		}
+
+		bc.mu.Lock()
+		defer bc.mu.Unlock()
+		delete(bc.refreshing, id)
+		if err == nil {
+			delete(bc.refreshFailures, id)
+			return
+		}
+		failure := bc.refreshFailures[id]
+		failure.retryAt = bc.now().Add(bc.refreshBackoff.Next(failure.attempts))
+		failure.attempts++
+		bc.refreshFailures[id] = failure
+	}()
+}
+
+func (bc *boltCacheMiddleware) expired(age time.Duration) bool {
+	return bc.ttl > 0 && age > bc.ttl
+}
+
+var (
+	// errCacheMiss is returned from get when there is no entry at the location.
+	errCacheMiss = errors.New("cache miss")
+
+	// errLegacyEntry is returned from get when the entry at the location is not a
+	// cacheEntry. The raw entry is returned as the entry's value.
+	errLegacyEntry = errors.New("cache entry is not wrapped with the time it was stored")
+)
+
+func (bc *boltCacheMiddleware) get(loc cacheLocation) (cacheEntry, error) {
+	var entry cacheEntry
+	err := bc.db.View(func(tx *bbolt.Tx) error {
+		b := loc.bucket(tx).Get(loc.key)
+		if b == nil {
+			return errCacheMiss
+		}
+		if err := valueDecode(b, &entry); err != nil {
+			entry.Value = append([]byte(nil), b...)
+			return errLegacyEntry
+		}
+		if entry.Version != cacheSchemaVersion {
+			return errCacheMiss
+		}
+		return nil
+	})
+	return entry, err
+}
+
+func (bc *boltCacheMiddleware) delete(loc cacheLocation) {
+	// safely ignore error here, the entry is overwritten on the next successful fetch regardless.
+	bc.db.Update(func(tx *bbolt.Tx) error {
+		return loc.bucket(tx).Delete(loc.key)
+	})
+}
 
+func (bc *boltCacheMiddleware) put(loc cacheLocation, v interface{}) {
 	// safely ignore error here, if we have an error we swallow it since it is not in the critical path.
 	bc.db.Update(func(tx *bbolt.Tx) error {
-		buf, err := valueEncoder(clientOut)
+		buf, err := valueEncoder(v)
 		if err != nil {
 			return err
 		}
-		b := tx.Bucket(bucket).Bucket(nestedBucket)
-		return b.Put(cacheID, buf.Bytes())
-	})
-
-	return clientOut, nil
-}
 
-func (bc *boltCacheMiddleware) Search(ctx context.Context, query SearchRequest) (SearchResponse, error) {
-	return bc.next.Search(ctx, query)
+		entry, err := valueEncoder(cacheEntry{
+			StoredAt: bc.now(),
+			Value:    buf.Bytes(),
+			Version:  cacheSchemaVersion,
+		})
+		if err != nil {
+			return err
+		}
+		return loc.bucket(tx).Put(loc.key, entry.Bytes())
+	})
 }
 
 func valueDecode(b []byte, v interface{}) error {
@@ -0,0 +1,71 @@
+package quranc
+
+import (
+	"context"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+// CacheBucket is a top level bucket of the bolt cache. Purging a bucket drops everything
+// cached in it, including its nested buckets, i.e. purging CacheBucketVerses drops the
+// cached verses, single verses, verse tafsiraat and verse translations.
+type CacheBucket string
+
+// Top level buckets of the bolt cache.
+const (
+	CacheBucketChapters     CacheBucket = bucketChapters
+	CacheBucketJuzzah       CacheBucket = bucketJuzzah
+	CacheBucketLanguages    CacheBucket = bucketLanguages
+	CacheBucketRecitations  CacheBucket = bucketRecitations
+	CacheBucketSearch       CacheBucket = bucketSearch
+	CacheBucketTafsiraat    CacheBucket = bucketTafsiraat
+	CacheBucketTranslations CacheBucket = bucketTranslations
+	CacheBucketVerses       CacheBucket = bucketVerses
+)
+
+// CachePurger is implemented by caches whose cached data can be dropped without deleting
+// the cache, i.e. the QuranAPI returned from BoltCache. This lets an operator drop stale
+// data after quran.com publishes an update, callers type assert the QuranAPI for it.
+type CachePurger interface {
+	PurgeBucket(ctx context.Context, bucket CacheBucket) error
+	PurgeAll(ctx context.Context) error
+}
+
+// PurgeBucket drops everything cached in the bucket. The bucket is deleted and recreated
+// in a single transaction, so readers never see it missing.
+func (bc *boltCacheMiddleware) PurgeBucket(ctx context.Context, bucket CacheBucket) error {
+	if _, ok := cacheBuckets[string(bucket)]; !ok {
+		return fmt.Errorf("invalid cache bucket %q", bucket)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return bc.db.Update(func(tx *bbolt.Tx) error {
+		return purgeBucket(tx, string(bucket))
+	})
+}
+
+// PurgeAll drops everything cached in every bucket in a single transaction.
+func (bc *boltCacheMiddleware) PurgeAll(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return bc.db.Update(func(tx *bbolt.Tx) error {
+		for bucket := range cacheBuckets {
+			if err := purgeBucket(tx, bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func purgeBucket(tx *bbolt.Tx, bucket string) error {
+	if err := tx.DeleteBucket([]byte(bucket)); err != nil && err != bbolt.ErrBucketNotFound {
+		return fmt.Errorf("delete bucket %q: %s", bucket, err)
+	}
+	return createBucket(tx, bucket)
+}
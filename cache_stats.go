@@ -0,0 +1,72 @@
+package quranc
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// CacheMethodStats are the cache stats of a single api method.
+type CacheMethodStats struct {
+	// Hits is the number of calls served from the cache.
+	Hits int64
+	// Misses is the number of calls the cache could not serve.
+	Misses int64
+	// Errors is the number of misses that failed to fetch.
+	Errors int64
+}
+
+// CacheStats are the stats a cache collected over its lifetime, keyed by api method.
+type CacheStats struct {
+	Methods map[string]CacheMethodStats
+}
+
+// CacheStatsReporter is implemented by caches that collect stats over their lifetime, i.e.
+// the QuranAPI returned from BoltCache. Unlike CallStats, which are collected per context,
+// these cover every call made through the cache. Callers type assert the QuranAPI for it.
+type CacheStatsReporter interface {
+	Stats() CacheStats
+}
+
+// cacheCounters counts the hits, misses and errors of the methods of a cache. The counters
+// are atomic so stats can be read while calls are in flight.
+type cacheCounters struct {
+	methods sync.Map
+}
+
+type methodCounters struct {
+	hits, misses, errors int64
+}
+
+func (c *cacheCounters) method(method string) *methodCounters {
+	if m, ok := c.methods.Load(method); ok {
+		return m.(*methodCounters)
+	}
+	m, _ := c.methods.LoadOrStore(method, new(methodCounters))
+	return m.(*methodCounters)
+}
+
+func (c *cacheCounters) hit(method string) {
+	atomic.AddInt64(&c.method(method).hits, 1)
+}
+
+func (c *cacheCounters) miss(method string) {
+	atomic.AddInt64(&c.method(method).misses, 1)
+}
+
+func (c *cacheCounters) error(method string) {
+	atomic.AddInt64(&c.method(method).errors, 1)
+}
+
+func (c *cacheCounters) stats() CacheStats {
+	stats := CacheStats{Methods: make(map[string]CacheMethodStats)}
+	c.methods.Range(func(k, v interface{}) bool {
+		m := v.(*methodCounters)
+		stats.Methods[k.(string)] = CacheMethodStats{
+			Hits:   atomic.LoadInt64(&m.hits),
+			Misses: atomic.LoadInt64(&m.misses),
+			Errors: atomic.LoadInt64(&m.errors),
+		}
+		return true
+	})
+	return stats
+}
@@ -0,0 +1,845 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// countingAPI is a QuranAPI that counts its calls by method. Every call returns a value
+// carrying its call number, so a test can tell a cached value from a fresh one.
+type countingAPI struct {
+	QuranAPI
+
+	// failChapter is a chapter whose verses fail to be fetched.
+	failChapter int
+	// failChapters makes the chapters fail to be fetched.
+	failChapters bool
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *countingAPI) call(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[method]++
+	return c.calls[method]
+}
+
+func (c *countingAPI) callCount(method string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[method]
+}
+
+func (c *countingAPI) Verse(ctx context.Context, chapterID, verseID int, reqOpts ...VersesReqOptFn) (Verse, error) {
+	return Verse{ID: c.call("Verse"), ChapterID: chapterID, VerseNumber: verseID}, nil
+}
+
+func (c *countingAPI) Chapters(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter, error) {
+	n := c.call("Chapters")
+	if c.failChapters {
+		return nil, errors.New("unavailable")
+	}
+	return []Chapter{{ID: n}}, nil
+}
+
+func (c *countingAPI) Chapter(ctx context.Context, id int, reqOpts ...ReqOptFn) (Chapter, error) {
+	c.call("Chapter")
+	return StaticChapters()[id-1], nil
+}
+
+// Verses pages through the chapter's verses the way the api does.
+func (c *countingAPI) Verses(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	n := c.call("Verses")
+	if chapterID == c.failChapter {
+		return nil, errors.New("unavailable")
+	}
+
+	var opts versesReqOpt
+	for _, o := range reqOpts {
+		opts = o(opts)
+	}
+	limit, page := opts.Limit, opts.Page
+	if limit < 1 {
+		limit = defaultVersesLimit
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	verses := []Verse{}
+	count := StaticChapters()[chapterID-1].VersesCount
+	for v := (page-1)*limit + opts.Offset + 1; v <= count && len(verses) < limit; v++ {
+		verses = append(verses, Verse{ID: n, ChapterID: chapterID, VerseNumber: v, VerseKey: verseKey(chapterID, v)})
+	}
+	return verses, nil
+}
+
+func (c *countingAPI) VerseTafsir(ctx context.Context, chapterID, verseID int, reqOpts ...VerseTafsirReqOptFn) ([]VerseTafsir, error) {
+	n := c.call("VerseTafsir")
+	return []VerseTafsir{{ID: n, VerseID: verseID, VerseKey: verseKey(chapterID, verseID)}}, nil
+}
+
+func newTestDB(t *testing.T) *bbolt.DB {
+	t.Helper()
+
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "cache.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// bucketLen returns the number of entries in the cache bucket at the path of buckets,
+// leaving out the buckets nested in it.
+func bucketLen(t *testing.T, db *bbolt.DB, buckets ...string) int {
+	t.Helper()
+
+	var n int
+	err := db.View(func(tx *bbolt.Tx) error {
+		return cacheLocation{buckets: buckets}.bucket(tx).ForEach(func(k, v []byte) error {
+			if v != nil {
+				n++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// fakeClock is a clock that only moves when it is advanced, or by step on every read.
+type fakeClock struct {
+	mu   sync.Mutex
+	t    time.Time
+	step time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.t
+	c.t = c.t.Add(c.step)
+	return now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// waitRefreshed waits for the cache's background refreshes to complete.
+func waitRefreshed(t *testing.T, api QuranAPI) {
+	t.Helper()
+
+	bc := api.(*boltCacheMiddleware)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		bc.mu.Lock()
+		n := len(bc.refreshing)
+		bc.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d refreshes still in flight", n)
+		}
+		runtime.Gosched()
+	}
+}
+
+func newTestBoltCache(t *testing.T, next QuranAPI, opts ...BoltCacheOptFn) QuranAPI {
+	t.Helper()
+
+	api, err := BoltCache(next, newTestDB(t), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func TestBoltCache_Verse(t *testing.T) {
+	t.Run("no cache refreshes the entry read without options", func(t *testing.T) {
+		ctx := context.Background()
+		next := new(countingAPI)
+		api := newTestBoltCache(t, next)
+
+		first, err := api.Verse(ctx, 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fresh, err := api.Verse(ctx, 1, 1, VersesNoCache())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fresh.ID == first.ID {
+			t.Fatalf("NoCache returned the cached verse %d", fresh.ID)
+		}
+
+		got, err := api.Verse(ctx, 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != fresh.ID {
+			t.Errorf("got verse %d, want the verse %d written by the NoCache call", got.ID, fresh.ID)
+		}
+		if calls := next.callCount("Verse"); calls != 2 {
+			t.Errorf("got %d upstream calls, want 2", calls)
+		}
+	})
+
+	t.Run("options that change the response are cached apart", func(t *testing.T) {
+		ctx := context.Background()
+		next := new(countingAPI)
+		api := newTestBoltCache(t, next)
+
+		plain, err := api.Verse(ctx, 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		translated, err := api.Verse(ctx, 1, 1, VersesTranslations([]int{20}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plain.ID == translated.ID {
+			t.Fatal("verses with and without translations share an entry")
+		}
+	})
+}
+
+func TestBoltCache_clock(t *testing.T) {
+	ctx := context.Background()
+
+	// read reads verse 1:1 through the cache, failing the test when the verse is not the
+	// one returned by the upstream call numbered wantID.
+	read := func(t *testing.T, api QuranAPI, wantID int) {
+		t.Helper()
+		v, err := api.Verse(ctx, 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.ID != wantID {
+			t.Fatalf("got the verse of call %d, want call %d", v.ID, wantID)
+		}
+	}
+
+	t.Run("entries expire after the ttl", func(t *testing.T) {
+		clock := newFakeClock()
+		next := new(countingAPI)
+		api := newTestBoltCache(t, next, BoltCacheTTL(time.Hour), WithClock(clock.now))
+
+		read(t, api, 1)
+		clock.advance(time.Hour)
+		read(t, api, 1)
+		clock.advance(time.Second)
+		read(t, api, 2)
+		read(t, api, 2)
+		if calls := next.callCount("Verse"); calls != 2 {
+			t.Errorf("got %d upstream calls, want 2", calls)
+		}
+	})
+
+	t.Run("entries within the refresh window are refreshed ahead", func(t *testing.T) {
+		clock := newFakeClock()
+		next := new(countingAPI)
+		api := newTestBoltCache(t, next,
+			BoltCacheTTL(time.Hour),
+			WithRefreshAhead(true),
+			RefreshAheadWindow(10*time.Minute),
+			WithClock(clock.now),
+		)
+
+		read(t, api, 1)
+		clock.advance(49 * time.Minute)
+		read(t, api, 1)
+		waitRefreshed(t, api)
+		if calls := next.callCount("Verse"); calls != 1 {
+			t.Fatalf("got %d upstream calls before the refresh window, want 1", calls)
+		}
+
+		clock.advance(time.Minute)
+		read(t, api, 1)
+		waitRefreshed(t, api)
+		if calls := next.callCount("Verse"); calls != 2 {
+			t.Fatalf("got %d upstream calls in the refresh window, want 2", calls)
+		}
+
+		// the refreshed entry was stored at the time of the refresh, 50 minutes on it is
+		// still outside of its own refresh window.
+		clock.advance(49 * time.Minute)
+		read(t, api, 2)
+		waitRefreshed(t, api)
+		if calls := next.callCount("Verse"); calls != 2 {
+			t.Errorf("got %d upstream calls, want 2", calls)
+		}
+	})
+}
+
+func TestBoltCache_purge(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name             string
+		purge            func(p CachePurger) error
+		wantErr          bool
+		wantVerseCalls   int
+		wantChapterCalls int
+	}{
+		{
+			name:             "verses",
+			purge:            func(p CachePurger) error { return p.PurgeBucket(context.Background(), CacheBucketVerses) },
+			wantVerseCalls:   2,
+			wantChapterCalls: 1,
+		},
+		{
+			name:             "chapters drops its nested buckets",
+			purge:            func(p CachePurger) error { return p.PurgeBucket(context.Background(), CacheBucketChapters) },
+			wantVerseCalls:   1,
+			wantChapterCalls: 2,
+		},
+		{
+			name:             "a bucket with nothing cached",
+			purge:            func(p CachePurger) error { return p.PurgeBucket(context.Background(), CacheBucketSearch) },
+			wantVerseCalls:   1,
+			wantChapterCalls: 1,
+		},
+		{
+			name:             "all",
+			purge:            func(p CachePurger) error { return p.PurgeAll(context.Background()) },
+			wantVerseCalls:   2,
+			wantChapterCalls: 2,
+		},
+		{
+			name:             "invalid bucket",
+			purge:            func(p CachePurger) error { return p.PurgeBucket(context.Background(), CacheBucket(bucketVerse)) },
+			wantErr:          true,
+			wantVerseCalls:   1,
+			wantChapterCalls: 1,
+		},
+		{
+			name:             "bucket with a done context",
+			purge:            func(p CachePurger) error { return p.PurgeBucket(cancelled, CacheBucketVerses) },
+			wantErr:          true,
+			wantVerseCalls:   1,
+			wantChapterCalls: 1,
+		},
+		{
+			name:             "all with a done context",
+			purge:            func(p CachePurger) error { return p.PurgeAll(cancelled) },
+			wantErr:          true,
+			wantVerseCalls:   1,
+			wantChapterCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			next := new(countingAPI)
+			api := newTestBoltCache(t, next)
+
+			read := func() {
+				t.Helper()
+				if _, err := api.Verse(ctx, 1, 1); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := api.Chapter(ctx, 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+			read()
+
+			purger, ok := api.(CachePurger)
+			if !ok {
+				t.Fatal("the bolt cache is not a CachePurger")
+			}
+			if err := tt.purge(purger); (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+
+			read()
+			if calls := next.callCount("Verse"); calls != tt.wantVerseCalls {
+				t.Errorf("got %d upstream Verse calls, want %d", calls, tt.wantVerseCalls)
+			}
+			if calls := next.callCount("Chapter"); calls != tt.wantChapterCalls {
+				t.Errorf("got %d upstream Chapter calls, want %d", calls, tt.wantChapterCalls)
+			}
+		})
+	}
+}
+
+func TestBoltCache_Stats(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		calls func(api QuranAPI)
+		want  map[string]CacheMethodStats
+	}{
+		{
+			name: "no calls",
+			want: map[string]CacheMethodStats{},
+		},
+		{
+			name: "a miss then a hit",
+			calls: func(api QuranAPI) {
+				api.Verse(ctx, 1, 1)
+				api.Verse(ctx, 1, 1)
+			},
+			want: map[string]CacheMethodStats{"Verse": {Hits: 1, Misses: 1}},
+		},
+		{
+			name: "no cache is a miss",
+			calls: func(api QuranAPI) {
+				api.Verse(ctx, 1, 1)
+				api.Verse(ctx, 1, 1, VersesNoCache())
+			},
+			want: map[string]CacheMethodStats{"Verse": {Misses: 2}},
+		},
+		{
+			name: "a failed fetch is a miss and an error",
+			calls: func(api QuranAPI) {
+				api.Verses(ctx, 2)
+				api.Verses(ctx, 2)
+			},
+			want: map[string]CacheMethodStats{"Verses": {Misses: 2, Errors: 2}},
+		},
+		{
+			name: "by method",
+			calls: func(api QuranAPI) {
+				api.Verse(ctx, 1, 1)
+				api.Verse(ctx, 1, 2)
+				api.Verse(ctx, 1, 1)
+				api.Chapter(ctx, 1)
+				api.Verses(ctx, 2)
+			},
+			want: map[string]CacheMethodStats{
+				"Verse":   {Hits: 1, Misses: 2},
+				"Chapter": {Misses: 1},
+				"Verses":  {Misses: 1, Errors: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestBoltCache(t, &countingAPI{failChapter: 2})
+			if tt.calls != nil {
+				tt.calls(api)
+			}
+
+			reporter, ok := api.(CacheStatsReporter)
+			if !ok {
+				t.Fatal("the bolt cache is not a CacheStatsReporter")
+			}
+			if got := reporter.Stats().Methods; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyBoltCache(t *testing.T) {
+	ctx := context.Background()
+	verse := cacheLocation{buckets: []string{bucketVerses, bucketVerse}, key: []byte("1:1")}
+
+	// warm creates the cache on the db, with verse 1:1 cached.
+	warm := func(t *testing.T, db *bbolt.DB) {
+		t.Helper()
+		api, err := BoltCache(new(countingAPI), db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := api.Verse(ctx, 1, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// put creates the cache on the db, with the raw value at the verse location.
+	put := func(t *testing.T, db *bbolt.DB, value []byte) {
+		t.Helper()
+		if _, err := BoltCache(new(countingAPI), db); err != nil {
+			t.Fatal(err)
+		}
+		err := db.Update(func(tx *bbolt.Tx) error {
+			return verse.bucket(tx).Put(verse.key, value)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// entry encodes the value as a cache entry of the schema version.
+	entry := func(t *testing.T, value interface{}, version int) []byte {
+		t.Helper()
+		v, err := valueEncoder(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := valueEncoder(cacheEntry{Value: v.Bytes(), Version: version})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e.Bytes()
+	}
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, db *bbolt.DB)
+		wantBuckets int
+		wantEntries int
+		wantIssues  []string
+	}{
+		{
+			name:        "warmed cache",
+			setup:       warm,
+			wantBuckets: 15,
+			wantEntries: 1,
+		},
+		{
+			name:       "empty db",
+			setup:      func(t *testing.T, db *bbolt.DB) {},
+			wantIssues: []string{`missing bucket "chapters"`, `missing bucket "verses"`},
+		},
+		{
+			name: "missing nested bucket",
+			setup: func(t *testing.T, db *bbolt.DB) {
+				warm(t, db)
+				err := db.Update(func(tx *bbolt.Tx) error {
+					return tx.Bucket([]byte(bucketVerses)).DeleteBucket([]byte(bucketVerseTafsir))
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantBuckets: 14,
+			wantEntries: 1,
+			wantIssues:  []string{`missing bucket "verses/` + bucketVerseTafsir + `"`},
+		},
+		{
+			name:        "old schema version",
+			setup:       func(t *testing.T, db *bbolt.DB) { put(t, db, entry(t, Verse{ID: 1}, cacheSchemaVersion-1)) },
+			wantBuckets: 15,
+			wantEntries: 1,
+			wantIssues:  []string{"schema version"},
+		},
+		{
+			name:        "entry that does not decode",
+			setup:       func(t *testing.T, db *bbolt.DB) { put(t, db, []byte("not an entry")) },
+			wantBuckets: 15,
+			wantEntries: 1,
+			wantIssues:  []string{"decode entry"},
+		},
+		{
+			name:        "value of another type",
+			setup:       func(t *testing.T, db *bbolt.DB) { put(t, db, entry(t, "1:1", cacheSchemaVersion)) },
+			wantBuckets: 15,
+			wantEntries: 1,
+			wantIssues:  []string{"decode Verse"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			tt.setup(t, db)
+
+			health, err := VerifyBoltCache(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if health.Buckets != tt.wantBuckets || health.Entries != tt.wantEntries {
+				t.Errorf("got %d buckets and %d entries, want %d and %d", health.Buckets, health.Entries, tt.wantBuckets, tt.wantEntries)
+			}
+			if health.Healthy() != (len(tt.wantIssues) == 0) {
+				t.Errorf("got issues %q, want %q", health.Issues, tt.wantIssues)
+			}
+			issues := strings.Join(health.Issues, "\n")
+			for _, want := range tt.wantIssues {
+				if !strings.Contains(issues, want) {
+					t.Errorf("got issues %q, want one with %q", health.Issues, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBoltCache_refreshAhead(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		opts      []BoltCacheOptFn
+		age       time.Duration
+		wantCalls int
+	}{
+		{
+			name:      "outside the default window",
+			opts:      []BoltCacheOptFn{BoltCacheTTL(time.Hour), WithRefreshAhead(true)},
+			age:       53 * time.Minute,
+			wantCalls: 1,
+		},
+		{
+			name:      "within the default window of a tenth of the ttl",
+			opts:      []BoltCacheOptFn{BoltCacheTTL(time.Hour), WithRefreshAhead(true)},
+			age:       54 * time.Minute,
+			wantCalls: 2,
+		},
+		{
+			name:      "within the window when disabled",
+			opts:      []BoltCacheOptFn{BoltCacheTTL(time.Hour), WithRefreshAhead(false)},
+			age:       59 * time.Minute,
+			wantCalls: 1,
+		},
+		{
+			name:      "without a ttl",
+			opts:      []BoltCacheOptFn{WithRefreshAhead(true), RefreshAheadWindow(time.Hour)},
+			age:       59 * time.Minute,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			next := new(countingAPI)
+			api := newTestBoltCache(t, next, append(tt.opts, WithClock(clock.now))...)
+
+			if _, err := api.Verse(ctx, 1, 1); err != nil {
+				t.Fatal(err)
+			}
+			clock.advance(tt.age)
+			v, err := api.Verse(ctx, 1, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.ID != 1 {
+				t.Errorf("got the verse of call %d, want the cached verse", v.ID)
+			}
+			waitRefreshed(t, api)
+			if calls := next.callCount("Verse"); calls != tt.wantCalls {
+				t.Errorf("got %d upstream calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	t.Run("failed refreshes are backed off from", func(t *testing.T) {
+		clock := newFakeClock()
+		next := new(countingAPI)
+		api := newTestBoltCache(t, next,
+			BoltCacheTTL(time.Hour),
+			WithRefreshAhead(true),
+			RefreshAheadBackoff(Backoff{Base: time.Minute, Max: time.Minute, Factor: 1}),
+			WithClock(clock.now),
+		)
+
+		// the verses are cached before the chapter starts failing.
+		if _, err := api.Verses(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		next.failChapter = 1
+
+		read := func(wantCalls int) {
+			t.Helper()
+			if _, err := api.Verses(ctx, 1); err != nil {
+				t.Fatal(err)
+			}
+			waitRefreshed(t, api)
+			if calls := next.callCount("Verses"); calls != wantCalls {
+				t.Fatalf("got %d upstream calls, want %d", calls, wantCalls)
+			}
+		}
+		clock.advance(55 * time.Minute)
+		read(2)
+		clock.advance(30 * time.Second)
+		read(2)
+		clock.advance(30 * time.Second)
+		read(3)
+	})
+}
+
+func TestBoltCache_ChaptersStale(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		age       time.Duration
+		fail      bool
+		stale     bool
+		wantID    int
+		wantStale bool
+		wantCalls int
+	}{
+		{name: "entry as old as the ttl is fresh", ttl: time.Hour, age: time.Hour, stale: true, wantID: 1, wantCalls: 1},
+		{name: "expired entry is served and refreshed", ttl: time.Hour, age: 2 * time.Hour, stale: true, wantID: 1, wantStale: true, wantCalls: 2},
+		{name: "expired entry that fails to refresh", ttl: time.Hour, age: 2 * time.Hour, fail: true, stale: true, wantID: 1, wantStale: true, wantCalls: 2},
+		{name: "entries without a ttl never expire", age: 1000 * time.Hour, stale: true, wantID: 1, wantCalls: 1},
+		{name: "Chapters refetches an expired entry", ttl: time.Hour, age: 2 * time.Hour, wantID: 2, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			next := new(countingAPI)
+			api := newTestBoltCache(t, next, BoltCacheTTL(tt.ttl), WithClock(clock.now))
+			staleAware, ok := api.(StaleAware)
+			if !ok {
+				t.Fatal("the bolt cache is not StaleAware")
+			}
+
+			if _, stale, err := staleAware.ChaptersStale(ctx); err != nil || stale {
+				t.Fatalf("got stale %t and error %v reading a miss", stale, err)
+			}
+			next.failChapters = tt.fail
+			clock.advance(tt.age)
+
+			var (
+				chapters []Chapter
+				stale    bool
+				err      error
+			)
+			if tt.stale {
+				chapters, stale, err = staleAware.ChaptersStale(ctx)
+			} else {
+				chapters, err = api.Chapters(ctx)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(chapters) != 1 || chapters[0].ID != tt.wantID || stale != tt.wantStale {
+				t.Errorf("got %+v stale %t, want the chapters of call %d stale %t", chapters, stale, tt.wantID, tt.wantStale)
+			}
+			waitRefreshed(t, api)
+			if calls := next.callCount("Chapters"); calls != tt.wantCalls {
+				t.Errorf("got %d upstream calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	t.Run("a refreshed entry is no longer stale", func(t *testing.T) {
+		clock := newFakeClock()
+		next := new(countingAPI)
+		api := newTestBoltCache(t, next, BoltCacheTTL(time.Hour), WithClock(clock.now))
+
+		api.Chapters(ctx)
+		clock.advance(2 * time.Hour)
+		if _, stale, err := api.(StaleAware).ChaptersStale(ctx); err != nil || !stale {
+			t.Fatalf("got stale %t and error %v, want the expired entry", stale, err)
+		}
+		waitRefreshed(t, api)
+
+		chapters, stale, err := api.(StaleAware).ChaptersStale(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stale || chapters[0].ID != 2 {
+			t.Errorf("got %+v stale %t, want the refreshed chapters", chapters, stale)
+		}
+	})
+}
+
+func TestBoltCache_onCorrupt(t *testing.T) {
+	ctx := context.Background()
+
+	type corruption struct {
+		bucket string
+		key    string
+	}
+	var corrupted []corruption
+	next := new(countingAPI)
+	db := newTestDB(t)
+	api, err := BoltCache(next, db, BoltCacheOnCorrupt(func(bucket string, key []byte, err error) {
+		if err == nil {
+			t.Error("got a corrupt entry without its error")
+		}
+		corrupted = append(corrupted, corruption{bucket: bucket, key: string(key)})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := api.Verse(ctx, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// the cached verse is overwritten with bytes that decode to neither an entry nor a verse.
+	var key string
+	err = db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(bucketVerses)).Bucket([]byte(bucketVerse))
+		k, _ := b.Cursor().First()
+		key = string(k)
+		return b.Put(k, []byte("not a verse"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := api.Verse(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []corruption{{bucket: bucketVerses + "/" + bucketVerse, key: key}}
+	if !reflect.DeepEqual(corrupted, want) {
+		t.Errorf("got corrupt entries %+v, want %+v", corrupted, want)
+	}
+	if v.ID != 2 {
+		t.Errorf("got the verse of call %d, want the corrupt entry refetched", v.ID)
+	}
+
+	v, err = api.Verse(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != 2 || len(corrupted) != 1 {
+		t.Errorf("got the verse of call %d with %d corrupt entries, want the replaced entry", v.ID, len(corrupted))
+	}
+	if calls := next.callCount("Verse"); calls != 2 {
+		t.Errorf("got %d upstream calls, want 2", calls)
+	}
+}
+
+func TestBoltCache_VerseTafsir(t *testing.T) {
+	ctx := context.Background()
+	next := new(countingAPI)
+	api := newTestBoltCache(t, next)
+
+	var keys []string
+	for i := 0; i < 2; i++ {
+		tafsirs, err := api.VerseTafsir(ctx, 2, 255, TafsirID(169))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tafsirs) != 1 {
+			t.Fatalf("got %d tafsirs, want 1", len(tafsirs))
+		}
+		keys = append(keys, tafsirs[0].VerseKey)
+	}
+	if keys[0] != "2:255" || keys[1] != keys[0] {
+		t.Errorf("got verse keys %q, want the cached tafsir to keep 2:255", keys)
+	}
+	if calls := next.callCount("VerseTafsir"); calls != 1 {
+		t.Errorf("got %d upstream calls, want the second served from the cache", calls)
+	}
+}
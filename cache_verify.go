@@ -0,0 +1,107 @@
+package quranc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.etcd.io/bbolt"
+)
+
+// cacheBucketTypes are the types of the values cached in each bucket, keyed by the path
+// to the bucket joined by "/".
+var cacheBucketTypes = map[string]interface{}{
+	bucketChapters:                           []Chapter(nil),
+	bucketChapters + "/" + bucketChapter:     Chapter{},
+	bucketChapters + "/" + bucketChapterInfo: ChapterInfo{},
+	bucketChapters + "/" + bucketInfoLangs:   []string(nil),
+	bucketChapters + "/" + bucketWordCount:   0,
+	bucketJuzzah:                             []Juz(nil),
+	bucketLanguages:                          []Language(nil),
+	bucketRecitations:                        []Recitation(nil),
+	bucketSearch:                             SearchResponse{},
+	bucketTafsiraat:                          []Tafsir(nil),
+	bucketTranslations:                       []Translation(nil),
+	bucketVerses:                             []Verse(nil),
+	bucketVerses + "/" + bucketVerse:         Verse{},
+	bucketVerses + "/" + bucketVerseTafsir:   []VerseTafsir(nil),
+	bucketVerses + "/" + bucketVerseTrans:    []Resource(nil),
+}
+
+// CacheHealth is the result of verifying a bolt cache file.
+type CacheHealth struct {
+	// Buckets is the number of buckets checked.
+	Buckets int
+	// Entries is the number of entries sampled.
+	Entries int
+	// Issues describes every problem found, it is empty for a healthy cache.
+	Issues []string
+}
+
+// Healthy reports whether no issues were found.
+func (h CacheHealth) Healthy() bool {
+	return len(h.Issues) == 0
+}
+
+// VerifyBoltCache checks a bolt cache file is usable before relying on it, i.e. after it
+// was pre-warmed elsewhere. Every bucket the cache expects must exist, and the first entry
+// of each bucket is sampled to check its schema version matches and its value decodes.
+// The db is only read from. Problems with the cache are reported as issues on the health,
+// the error is reserved for failing to read the db.
+func VerifyBoltCache(db *bbolt.DB) (CacheHealth, error) {
+	var health CacheHealth
+	err := db.View(func(tx *bbolt.Tx) error {
+		for bucket, nestedBuckets := range cacheBuckets {
+			b := tx.Bucket([]byte(bucket))
+			if b == nil {
+				health.Issues = append(health.Issues, fmt.Sprintf("missing bucket %q", bucket))
+				continue
+			}
+			health.Buckets++
+			health.verifyBucket(bucket, b)
+
+			for _, nestedBucket := range nestedBuckets {
+				path := bucket + "/" + nestedBucket
+				nb := b.Bucket([]byte(nestedBucket))
+				if nb == nil {
+					health.Issues = append(health.Issues, fmt.Sprintf("missing bucket %q", path))
+					continue
+				}
+				health.Buckets++
+				health.verifyBucket(path, nb)
+			}
+		}
+		return nil
+	})
+	return health, err
+}
+
+// verifyBucket samples the first entry of the bucket, nested buckets are skipped.
+func (h *CacheHealth) verifyBucket(path string, b *bbolt.Bucket) {
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if v == nil {
+			continue
+		}
+		h.Entries++
+
+		var entry cacheEntry
+		if err := valueDecode(v, &entry); err != nil {
+			h.Issues = append(h.Issues, fmt.Sprintf("bucket %q key %q: decode entry: %s", path, k, err))
+			return
+		}
+		if entry.Version != cacheSchemaVersion {
+			h.Issues = append(h.Issues, fmt.Sprintf("bucket %q key %q: schema version %d, want %d", path, k, entry.Version, cacheSchemaVersion))
+			return
+		}
+
+		typ := reflect.TypeOf(cacheBucketTypes[path])
+		if typ == nil {
+			return
+		}
+		if err := valueDecode(entry.Value, reflect.New(typ).Interface()); err != nil {
+			h.Issues = append(h.Issues, fmt.Sprintf("bucket %q key %q: decode %s: %s", path, k, strings.TrimPrefix(typ.String(), "quranc."), err))
+		}
+		return
+	}
+}
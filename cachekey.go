@@ -0,0 +1,29 @@
+package quranc
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// optionsCacheKey returns the cache key of a call made with the options and ids provided,
+// the gob encoding of the options struct followed by the ids. Every cached method builds
+// its key here, so the options structs follow a convention:
+//
+//   - every option that changes the response is an exported field, so it is part of
+//     the key. An option left unexported is silently left out of the key, and calls
+//     that differ only by it collide on the same entry.
+//   - options that do not change the response, i.e. NoCache, are unexported so they do
+//     not split the cache.
+//   - slices whose order does not change the response are sorted before the key is
+//     built, so the same set of ids always maps to the same key.
+func optionsCacheKey(opts interface{}, ids ...int) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(opts); err != nil {
+		return nil, err
+	}
+	if err := enc.Encode(ids); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
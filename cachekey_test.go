@@ -0,0 +1,118 @@
+package quranc
+
+import "testing"
+
+func TestOptionsCacheKey(t *testing.T) {
+	type keyCase struct {
+		name string
+		key  func() ([]byte, error)
+	}
+
+	reqOptKey := func(ids []int, reqOpts ...ReqOptFn) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			var opt reqOpt
+			for _, o := range reqOpts {
+				opt = o(opt)
+			}
+			return opt.key(ids...)
+		}
+	}
+	tafsirOptKey := func(chapterID, verseID int, reqOpts ...VerseTafsirReqOptFn) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			var opt verseTafsirReqOpts
+			for _, o := range reqOpts {
+				opt = o(opt)
+			}
+			return opt.key(chapterID, verseID)
+		}
+	}
+	searchKey := func(query SearchRequest) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			return optionsCacheKey(query)
+		}
+	}
+	translationsKey := func(key VerseKey, ids ...int) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			return optionsCacheKey(ids, key.Chapter, key.Verse)
+		}
+	}
+
+	// the keys of each endpoint's calls must be pairwise distinct, the endpoints are cached
+	// in buckets of their own so their keys may overlap one another. The verse options are
+	// covered by TestVersesReqOpt_key.
+	endpoints := []struct {
+		name  string
+		cases []keyCase
+	}{
+		{
+			name: "resource lists",
+			cases: []keyCase{
+				{name: "no options", key: reqOptKey(nil)},
+				{name: "language", key: reqOptKey(nil, LanguageID(1))},
+				{name: "other language", key: reqOptKey(nil, LanguageID(2))},
+				{name: "fields", key: reqOptKey(nil, ChaptersFields("id"))},
+				{name: "other fields", key: reqOptKey(nil, ChaptersFields("id", "name_simple"))},
+				{name: "language and fields", key: reqOptKey(nil, LanguageID(1), ChaptersFields("id"))},
+			},
+		},
+		{
+			name: "chapter",
+			cases: []keyCase{
+				{name: "chapter 1", key: reqOptKey([]int{1})},
+				{name: "chapter 2", key: reqOptKey([]int{2})},
+				{name: "chapter 1 language", key: reqOptKey([]int{1}, LanguageID(1))},
+				{name: "chapter 2 language", key: reqOptKey([]int{2}, LanguageID(1))},
+				{name: "chapter 1 fields", key: reqOptKey([]int{1}, ChaptersFields("id"))},
+			},
+		},
+		{
+			name: "verse tafsir",
+			cases: []keyCase{
+				{name: "1:1", key: tafsirOptKey(1, 1)},
+				{name: "1:2", key: tafsirOptKey(1, 2)},
+				{name: "2:1", key: tafsirOptKey(2, 1)},
+				{name: "11:1", key: tafsirOptKey(11, 1)},
+				{name: "1:11", key: tafsirOptKey(1, 11)},
+				{name: "1:1 tafsir", key: tafsirOptKey(1, 1, TafsirID(169))},
+				{name: "1:1 other tafsir", key: tafsirOptKey(1, 1, TafsirID(170))},
+			},
+		},
+		{
+			name: "verse translations",
+			cases: []keyCase{
+				{name: "1:1", key: translationsKey(VerseKey{Chapter: 1, Verse: 1})},
+				{name: "1:2", key: translationsKey(VerseKey{Chapter: 1, Verse: 2})},
+				{name: "2:1", key: translationsKey(VerseKey{Chapter: 2, Verse: 1})},
+				{name: "1:1 translation", key: translationsKey(VerseKey{Chapter: 1, Verse: 1}, 20)},
+				{name: "1:1 translations", key: translationsKey(VerseKey{Chapter: 1, Verse: 1}, 20, 85)},
+			},
+		},
+		{
+			name: "search",
+			cases: []keyCase{
+				{name: "query", key: searchKey(SearchRequest{Query: "mercy"})},
+				{name: "other query", key: searchKey(SearchRequest{Query: "light"})},
+				{name: "language", key: searchKey(SearchRequest{Query: "mercy", Language: "en"})},
+				{name: "page", key: searchKey(SearchRequest{Query: "mercy", Page: 2})},
+				{name: "size", key: searchKey(SearchRequest{Query: "mercy", Size: 2})},
+			},
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		t.Run(endpoint.name, func(t *testing.T) {
+			seen := make(map[string]string, len(endpoint.cases))
+			for _, c := range endpoint.cases {
+				key, err := c.key()
+				if err != nil {
+					t.Fatalf("%s: %s", c.name, err)
+				}
+				if other, ok := seen[string(key)]; ok {
+					t.Errorf("%s has the same key as %s", c.name, other)
+					continue
+				}
+				seen[string(key)] = c.name
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package quranc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MethodStats are the stats collected for a single api method.
+type MethodStats struct {
+	// Calls is the number of requests sent to quran.com.
+	Calls int
+	// Errors is the number of requests that failed.
+	Errors int
+	// Latency is the total time spent on requests.
+	Latency time.Duration
+	// CacheHits is the number of calls served from the cache.
+	CacheHits int
+	// CacheMisses is the number of calls the cache could not serve.
+	CacheMisses int
+}
+
+// AvgLatency returns the average latency of the requests sent.
+func (m MethodStats) AvgLatency() time.Duration {
+	if m.Calls == 0 {
+		return 0
+	}
+	return m.Latency / time.Duration(m.Calls)
+}
+
+// CallStats collects per method latency and cache hits and misses for the calls made
+// with a context returned from WithCallStats. It is owned by the caller, so a single
+// request, or a group of them, can collect its own stats. It is safe for concurrent use.
+type CallStats struct {
+	mu      sync.Mutex
+	methods map[string]MethodStats
+}
+
+// Methods returns a copy of the stats collected so far, keyed by the method name.
+func (s *CallStats) Methods() map[string]MethodStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]MethodStats, len(s.methods))
+	for method, stats := range s.methods {
+		out[method] = stats
+	}
+	return out
+}
+
+func (s *CallStats) recordCall(method string, latency time.Duration, err error) {
+	s.update(method, func(m *MethodStats) {
+		m.Calls++
+		m.Latency += latency
+		if err != nil {
+			m.Errors++
+		}
+	})
+}
+
+func (s *CallStats) recordCache(method string, hit bool) {
+	s.update(method, func(m *MethodStats) {
+		if hit {
+			m.CacheHits++
+		} else {
+			m.CacheMisses++
+		}
+	})
+}
+
+func (s *CallStats) update(method string, fn func(m *MethodStats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.methods == nil {
+		s.methods = make(map[string]MethodStats)
+	}
+	m := s.methods[method]
+	fn(&m)
+	s.methods[method] = m
+}
+
+type callStatsKey struct{}
+
+// WithCallStats returns a context that collects the stats of every call made with it
+// into stats. The stats are carried on the context rather than set as a ClientOptFn, so
+// they reach every layer the call passes through, i.e. a BoltCache wrapping the Client
+// records its hits and misses into the same stats, and each request can collect its
+// own stats from a client shared across requests.
+func WithCallStats(ctx context.Context, stats *CallStats) context.Context {
+	return context.WithValue(ctx, callStatsKey{}, stats)
+}
+
+func callStatsFromContext(ctx context.Context) *CallStats {
+	stats, _ := ctx.Value(callStatsKey{}).(*CallStats)
+	return stats
+}
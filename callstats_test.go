@@ -0,0 +1,100 @@
+package quranc
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithCallStats(t *testing.T) {
+	// the stub serves the chapters, and fails every verse.
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/chapters" {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"chapters": []Chapter{{ID: 1, VersesCount: 7}}})
+	})
+	// every read of the clock moves it a millisecond on, so each call takes a millisecond.
+	newClient := func(t *testing.T) *Client {
+		clock := newFakeClock()
+		clock.step = time.Millisecond
+		return newHandlerClient(t, h, WithClientClock(clock.now))
+	}
+
+	tests := []struct {
+		name  string
+		calls func(ctx context.Context, api QuranAPI)
+		want  map[string]MethodStats
+	}{
+		{
+			name: "no calls",
+			want: map[string]MethodStats{},
+		},
+		{
+			name: "calls accumulate",
+			calls: func(ctx context.Context, api QuranAPI) {
+				api.Chapters(ctx)
+				api.Chapters(ctx)
+				api.Verse(ctx, 1, 1)
+			},
+			want: map[string]MethodStats{
+				"Chapters": {Calls: 2, Latency: 2 * time.Millisecond},
+				"Verse":    {Calls: 1, Errors: 1, Latency: time.Millisecond},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats CallStats
+			ctx := WithCallStats(context.Background(), &stats)
+			if tt.calls != nil {
+				tt.calls(ctx, newClient(t))
+			}
+			if got := stats.Methods(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("through a cache", func(t *testing.T) {
+		api := newTestBoltCache(t, newClient(t))
+
+		var stats CallStats
+		ctx := WithCallStats(context.Background(), &stats)
+		for i := 0; i < 3; i++ {
+			if _, err := api.Chapters(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		want := map[string]MethodStats{
+			"Chapters": {Calls: 1, Latency: time.Millisecond, CacheHits: 2, CacheMisses: 1},
+		}
+		got := stats.Methods()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+		if avg := got["Chapters"].AvgLatency(); avg != time.Millisecond {
+			t.Errorf("got an average latency of %s, want 1ms", avg)
+		}
+	})
+
+	t.Run("contexts collect their own stats", func(t *testing.T) {
+		c := newClient(t)
+
+		var first, second CallStats
+		c.Chapters(WithCallStats(context.Background(), &first))
+		c.Chapters(WithCallStats(context.Background(), &second))
+		c.Chapters(context.Background())
+
+		for _, stats := range []*CallStats{&first, &second} {
+			if calls := stats.Methods()["Chapters"].Calls; calls != 1 {
+				t.Errorf("got %d calls, want 1", calls)
+			}
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package quranc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// ChapterCount is the number of chapters in the Qur'an.
+	ChapterCount = 114
+
+	// ChapterAlFatihah is the opening chapter, its basmala is its first verse.
+	ChapterAlFatihah = 1
+
+	// ChapterAtTawbah is the only chapter that is not preceded by a basmala.
+	ChapterAtTawbah = 9
+)
+
+// HasBismillah reports whether the basmala should be rendered before the chapter's first
+// verse. At-Tawbah is never preceded by a basmala, regardless of what the api reports.
+// Al-Fatihah's basmala is its first verse, the api reports it without bismillah_pre so
+// it is rendered as part of the verses rather than before them.
+func (c Chapter) HasBismillah() bool {
+	return c.BismillahPre && c.ID != ChapterAtTawbah
+}
+
+// RevelationPlace is the place a chapter was revealed in.
+type RevelationPlace string
+
+// Places a chapter was revealed in.
+const (
+	RevelationPlaceUnknown RevelationPlace = "unknown"
+	RevelationPlaceMakkah  RevelationPlace = "makkah"
+	RevelationPlaceMadinah RevelationPlace = "madinah"
+)
+
+// Place parses the chapter's revelation place case-insensitively. RevelationPlaceUnknown
+// is returned for a place other than makkah or madinah.
+func (c Chapter) Place() RevelationPlace {
+	switch place := RevelationPlace(strings.ToLower(strings.TrimSpace(c.RevelationPlace))); place {
+	case RevelationPlaceMakkah, RevelationPlaceMadinah:
+		return place
+	default:
+		return RevelationPlaceUnknown
+	}
+}
+
+// ChaptersRevealedIn returns the chapters of the api revealed in the place provided, in
+// the order the api returns them.
+func ChaptersRevealedIn(ctx context.Context, api QuranAPI, place RevelationPlace) ([]Chapter, error) {
+	chapters, err := api.Chapters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revealed := make([]Chapter, 0, len(chapters))
+	for _, ch := range chapters {
+		if ch.Place() == place {
+			revealed = append(revealed, ch)
+		}
+	}
+	return revealed, nil
+}
+
+// ChapterVerseBounds returns the keys of the first and last verses of the chapter, i.e.
+// "2:1" and "2:286" for Al-Baqarah. The bounds are derived from the chapter's verse count
+// looked up through the api provided.
+func ChapterVerseBounds(ctx context.Context, api QuranAPI, chapterID int) (first, last string, err error) {
+	if err := validateChapter(chapterID); err != nil {
+		return "", "", err
+	}
+
+	chapter, err := api.Chapter(ctx, chapterID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return verseKey(chapterID, 1), verseKey(chapterID, chapter.VersesCount), nil
+}
+
+func verseKey(chapterID, verseNumber int) string {
+	return strconv.Itoa(chapterID) + ":" + strconv.Itoa(verseNumber)
+}
+
+// VerseKey identifies a verse by its chapter and verse number, i.e. 2:255.
+type VerseKey struct {
+	Chapter int
+	Verse   int
+}
+
+// ParseVerseKey parses a verse key in the chapter:verse form, i.e. "2:255". The chapter
+// must be between 1 and 114 and the verse must be within the chapter.
+func ParseVerseKey(s string) (VerseKey, error) {
+	chapterID, verseNumber, err := parseVerseKey(s)
+	if err != nil {
+		return VerseKey{}, err
+	}
+	return VerseKey{Chapter: chapterID, Verse: verseNumber}, nil
+}
+
+// String returns the key in the chapter:verse form quran.com uses.
+func (k VerseKey) String() string {
+	return verseKey(k.Chapter, k.Verse)
+}
+
+// Key returns the key of the verse, built from its chapter and verse number.
+func (v Verse) Key() VerseKey {
+	return VerseKey{Chapter: v.ChapterID, Verse: v.VerseNumber}
+}
+
+// parseVerseKey parses a verse key, i.e. "2:255", into its chapter and verse number. The
+// chapter must be valid and the verse number within the chapter's verse count.
+func parseVerseKey(key string) (chapterID, verseNumber int, err error) {
+	parts := strings.Split(key, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid verse key %q: must be chapter:verse", key)
+	}
+
+	chapterID, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid verse key %q: chapter is not a number", key)
+	}
+	if err := validateChapter(chapterID); err != nil {
+		return 0, 0, fmt.Errorf("invalid verse key %q: %w", key, err)
+	}
+
+	verseNumber, err = strconv.Atoi(parts[1])
+	if err != nil || verseNumber < 1 {
+		return 0, 0, fmt.Errorf("invalid verse key %q: verse must be a positive number", key)
+	}
+	if count := StaticChapters()[chapterID-1].VersesCount; verseNumber > count {
+		return 0, 0, fmt.Errorf("invalid verse key %q: chapter %d has %d verses", key, chapterID, count)
+	}
+	return chapterID, verseNumber, nil
+}
+
+func validateChapter(chapterID int) error {
+	if chapterID < 1 || chapterID > ChapterCount {
+		return fmt.Errorf("invalid chapter %d: must be between 1 and %d", chapterID, ChapterCount)
+	}
+	return nil
+}
+
+// AllVerseKeys returns the key of every verse in the Qur'an, from "1:1" through "114:6",
+// generated from the chapter list of the api without fetching any verses.
+func AllVerseKeys(ctx context.Context, api QuranAPI) ([]string, error) {
+	chapters, err := api.Chapters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].ChapterNumber < chapters[j].ChapterNumber
+	})
+
+	keys := make([]string, 0, totalVerses)
+	for _, ch := range chapters {
+		for verse := 1; verse <= ch.VersesCount; verse++ {
+			keys = append(keys, verseKey(ch.ChapterNumber, verse))
+		}
+	}
+	return keys, nil
+}
+
+// ChapterByName returns the chapter of the api whose simple, complex or arabic name
+// matches the name provided, i.e. "Al-Fatihah" or "البقرة". Names are matched ignoring
+// case, punctuation and diacritics, with or without their article, i.e. "Nas" matches
+// "An-Nas". When no name matches, the chapter whose name is within two edits of it is
+// returned, if there is exactly one closest such chapter. ErrNotFound is returned when
+// nothing matches.
+func ChapterByName(ctx context.Context, api QuranAPI, name string, reqOpts ...ReqOptFn) (Chapter, error) {
+	chapters, err := api.Chapters(ctx, reqOpts...)
+	if err != nil {
+		return Chapter{}, err
+	}
+
+	wants := chapterNameForms(name)
+	if len(wants) == 0 {
+		return Chapter{}, fmt.Errorf("chapter %q: %w", name, ErrNotFound)
+	}
+
+	const maxDistance = 2
+	var (
+		closest   Chapter
+		best      = maxDistance + 1
+		ambiguous bool
+	)
+	for _, ch := range chapters {
+		for _, n := range []string{ch.NameSimple, ch.NameComplex, ch.NameArabic} {
+			for _, got := range chapterNameForms(n) {
+				for _, want := range wants {
+					if got == want {
+						return ch, nil
+					}
+
+					d := editDistance(got, want)
+					switch {
+					case d < best:
+						closest, best, ambiguous = ch, d, false
+					case d == best && ch.ID != closest.ID:
+						ambiguous = true
+					}
+				}
+			}
+		}
+	}
+	if best > maxDistance || ambiguous {
+		return Chapter{}, fmt.Errorf("chapter %q: %w", name, ErrNotFound)
+	}
+	return closest, nil
+}
+
+// chapterNameFolds folds the transliteration and arabic letter variants of chapter names
+// to their plain form.
+var chapterNameFolds = strings.NewReplacer(
+	"á", "a", "ā", "a", "â", "a", "đ", "d", "ḍ", "d", "ĥ", "h", "ḥ", "h",
+	"ī", "i", "î", "i", "ş", "s", "ṣ", "s", "ţ", "t", "ṭ", "t", "ū", "u", "û", "u",
+	"ẓ", "z", "أ", "ا", "إ", "ا", "آ", "ا", "ٱ", "ا", "ة", "ه", "ى", "ي", "ـ", "",
+)
+
+// chapterNameArticle matches the article of a transliterated chapter name, including its
+// assimilated forms, i.e. "al-", "an-" and "ash-". It must be separated from the name by
+// a hyphen or space, so names that merely start with the same letters, i.e. "Alaq", keep
+// them. The arabic article is written joined to the name.
+var chapterNameArticle = regexp.MustCompile(`^(?:a(?:l|n|r|s|sh|t|th|d|dh|z)[- ]+|ال)`)
+
+// chapterNameForms returns the forms a chapter name is matched by, the name lower cased
+// with its letter variants folded and everything but letters and digits dropped, and
+// the same without its article when it has one.
+func chapterNameForms(name string) []string {
+	name = strings.TrimSpace(chapterNameFolds.Replace(strings.ToLower(name)))
+
+	var forms []string
+	for _, n := range []string{name, chapterNameArticle.ReplaceAllString(name, "")} {
+		var b strings.Builder
+		for _, r := range n {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(r)
+			}
+		}
+		if form := b.String(); form != "" && (len(forms) == 0 || forms[0] != form) {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
+
+// editDistance returns the levenshtein distance between a and b, counted in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
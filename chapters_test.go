@@ -0,0 +1,410 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// staticChaptersAPI is a QuranAPI serving the embedded chapters.
+type staticChaptersAPI struct {
+	QuranAPI
+}
+
+func (staticChaptersAPI) Chapters(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter, error) {
+	return StaticChapters(), nil
+}
+
+// emptyAPI is a QuranAPI whose lists are all empty.
+type emptyAPI struct {
+	QuranAPI
+}
+
+func (emptyAPI) Chapters(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter, error) {
+	return []Chapter{}, nil
+}
+
+func (emptyAPI) Tafsiraat(ctx context.Context, reqOpts ...ReqOptFn) ([]Tafsir, error) {
+	return []Tafsir{}, nil
+}
+
+func (emptyAPI) VerseTafsir(ctx context.Context, chapterID, verseID int, reqOpts ...VerseTafsirReqOptFn) ([]VerseTafsir, error) {
+	return []VerseTafsir{}, nil
+}
+
+func TestChaptersRevealedIn(t *testing.T) {
+	t.Run("no chapters revealed in the place is empty", func(t *testing.T) {
+		got, err := ChaptersRevealedIn(context.Background(), staticChaptersAPI{}, RevelationPlaceUnknown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("got %#v, want an empty non-nil slice", got)
+		}
+	})
+
+	t.Run("madinah", func(t *testing.T) {
+		got, err := ChaptersRevealedIn(context.Background(), staticChaptersAPI{}, RevelationPlaceMadinah)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 28 {
+			t.Errorf("got %d chapters, want 28", len(got))
+		}
+	})
+}
+
+func TestAllVerseKeys(t *testing.T) {
+	t.Run("no chapters is empty", func(t *testing.T) {
+		got, err := AllVerseKeys(context.Background(), emptyAPI{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("got %#v, want an empty non-nil slice", got)
+		}
+	})
+
+	t.Run("every verse", func(t *testing.T) {
+		got, err := AllVerseKeys(context.Background(), staticChaptersAPI{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != totalVerses {
+			t.Fatalf("got %d keys, want %d", len(got), totalVerses)
+		}
+		if got[0] != "1:1" || got[len(got)-1] != "114:6" {
+			t.Errorf("got keys from %s to %s, want 1:1 to 114:6", got[0], got[len(got)-1])
+		}
+	})
+}
+
+func TestChapterByName(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{name: "Al-Fatihah", want: 1},
+		{name: "fatihah", want: 1},
+		{name: "البقرة", want: 2},
+		{name: "بقرة", want: 2},
+		{name: "ٱلْبَقَرَة", want: 2},
+		{name: "al baqara", want: 2},
+		{name: "Ali 'Imran", want: 3},
+		{name: "Yā-Sīn", want: 36},
+		{name: "Ar-Rahman", want: 55},
+		{name: "Rahman", want: 55},
+		{name: "Al-A'la", want: 87},
+		{name: "Ala", want: 87},
+		{name: "Ash-Sharh", want: 94},
+		{name: "Sharh", want: 94},
+		{name: "At-Tin", want: 95},
+		{name: "Tin", want: 95},
+		{name: "Al-'Alaq", want: 96},
+		{name: "Alaq", want: 96},
+		{name: "An-Nas", want: 114},
+		{name: "Nas", want: 114},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch, err := ChapterByName(context.Background(), staticChaptersAPI{}, tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ch.ID != tt.want {
+				t.Errorf("got chapter %d, want %d", ch.ID, tt.want)
+			}
+		})
+	}
+
+	t.Run("no match", func(t *testing.T) {
+		for _, name := range []string{"", "xyzzy", "-"} {
+			_, err := ChapterByName(context.Background(), staticChaptersAPI{}, name)
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("%q: got %v, want ErrNotFound", name, err)
+			}
+		}
+	})
+}
+
+func TestChapterNameForms(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "Alaq", want: []string{"alaq"}},
+		{name: "Ala", want: []string{"ala"}},
+		{name: "Alam", want: []string{"alam"}},
+		{name: "Al-'Alaq", want: []string{"alalaq", "alaq"}},
+		{name: "An-Nas", want: []string{"annas", "nas"}},
+		{name: "Ar-Raĥmān", want: []string{"arrahman", "rahman"}},
+		{name: "At-Tin", want: []string{"attin", "tin"}},
+		{name: "Ash-Sharh", want: []string{"ashsharh", "sharh"}},
+		{name: "Adh-Dhariyat", want: []string{"adhdhariyat", "dhariyat"}},
+		{name: "al baqara", want: []string{"albaqara", "baqara"}},
+		{name: "Ali 'Imran", want: []string{"aliimran"}},
+		{name: "الناس", want: []string{"الناس", "ناس"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chapterNameForms(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVerseKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    VerseKey
+		wantErr bool
+	}{
+		{key: "1:1", want: VerseKey{Chapter: 1, Verse: 1}},
+		{key: "2:255", want: VerseKey{Chapter: 2, Verse: 255}},
+		{key: "2:286", want: VerseKey{Chapter: 2, Verse: 286}},
+		{key: "114:6", want: VerseKey{Chapter: 114, Verse: 6}},
+		{key: "0:1", wantErr: true},
+		{key: "115:1", wantErr: true},
+		{key: "-1:1", wantErr: true},
+		{key: "1:0", wantErr: true},
+		{key: "1:-1", wantErr: true},
+		{key: "1:8", wantErr: true},
+		{key: "2:287", wantErr: true},
+		{key: "114:7", wantErr: true},
+		{key: "a:1", wantErr: true},
+		{key: "1:a", wantErr: true},
+		{key: "1", wantErr: true},
+		{key: "1:1:1", wantErr: true},
+		{key: ":", wantErr: true},
+		{key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := ParseVerseKey(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if s := got.String(); s != tt.key {
+				t.Errorf("got string %q, want %q", s, tt.key)
+			}
+		})
+	}
+}
+
+func TestClient_ChaptersFields(t *testing.T) {
+	const body = `{"chapters": [{"id": 2, "name_simple": "Al-Baqarah", "verses_count": 286}, {"id": 1, "name_simple": "Al-Fatihah", "verses_count": 7}]}`
+
+	tests := []struct {
+		name       string
+		version    string
+		reqOpts    []ReqOptFn
+		wantFields string
+	}{
+		{name: "every field", version: apiV3},
+		{name: "fields", version: apiV3, reqOpts: []ReqOptFn{ChaptersFields("id", "name_simple", "verses_count")}, wantFields: "id,name_simple,verses_count"},
+		{name: "v4 fields", version: apiV4, reqOpts: []ReqOptFn{ChaptersFields("id", "verses_count")}, wantFields: "id,verses_count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query url.Values
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				w.Write([]byte(body))
+			})
+			c := newHandlerClient(t, h, WithAPIVersion(tt.version))
+
+			chapters, err := c.Chapters(context.Background(), tt.reqOpts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := query.Get("fields"); got != tt.wantFields || query.Has("fields") != (tt.wantFields != "") {
+				t.Errorf("got fields %q, want %q", got, tt.wantFields)
+			}
+
+			want := []Chapter{
+				{ID: 1, ChapterNumber: 1, NameSimple: "Al-Fatihah", VersesCount: 7},
+				{ID: 2, ChapterNumber: 2, NameSimple: "Al-Baqarah", VersesCount: 286},
+			}
+			if !reflect.DeepEqual(chapters, want) {
+				t.Errorf("got %+v, want %+v", chapters, want)
+			}
+		})
+	}
+}
+
+func TestChapter_HasBismillah(t *testing.T) {
+	tests := []struct {
+		name    string
+		chapter Chapter
+		want    bool
+	}{
+		{name: "Al-Fatihah", chapter: Chapter{ID: 1, BismillahPre: false}},
+		{name: "Al-Baqarah", chapter: Chapter{ID: 2, BismillahPre: true}, want: true},
+		{name: "At-Tawbah", chapter: Chapter{ID: ChapterAtTawbah, BismillahPre: false}},
+		{name: "At-Tawbah reported with bismillah_pre", chapter: Chapter{ID: ChapterAtTawbah, BismillahPre: true}},
+		{name: "chapter reported without bismillah_pre", chapter: Chapter{ID: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.chapter.HasBismillah(); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChapterVerseBounds(t *testing.T) {
+	tests := []struct {
+		chapter   int
+		wantFirst string
+		wantLast  string
+		wantErr   bool
+	}{
+		{chapter: 1, wantFirst: "1:1", wantLast: "1:7"},
+		{chapter: 2, wantFirst: "2:1", wantLast: "2:286"},
+		{chapter: 114, wantFirst: "114:1", wantLast: "114:6"},
+		{chapter: 0, wantErr: true},
+		{chapter: 115, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.chapter), func(t *testing.T) {
+			api := new(countingAPI)
+
+			first, last, err := ChapterVerseBounds(context.Background(), api, tt.chapter)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %s to %s, want an error", first, last)
+				}
+				if calls := api.callCount("Chapter"); calls != 0 {
+					t.Errorf("got %d calls for an invalid chapter", calls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first != tt.wantFirst || last != tt.wantLast {
+				t.Errorf("got %s to %s, want %s to %s", first, last, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestChapter_Place(t *testing.T) {
+	tests := []struct {
+		place string
+		want  RevelationPlace
+	}{
+		{place: "makkah", want: RevelationPlaceMakkah},
+		{place: "madinah", want: RevelationPlaceMadinah},
+		{place: "Makkah", want: RevelationPlaceMakkah},
+		{place: "MADINAH", want: RevelationPlaceMadinah},
+		{place: "  makkah\n", want: RevelationPlaceMakkah},
+		{place: " MaDiNaH ", want: RevelationPlaceMadinah},
+		{place: "", want: RevelationPlaceUnknown},
+		{place: "mecca", want: RevelationPlaceUnknown},
+		{place: "unknown", want: RevelationPlaceUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.place, func(t *testing.T) {
+			if got := (Chapter{RevelationPlace: tt.place}).Place(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ChapterInfoLanguages(t *testing.T) {
+	ctx := context.Background()
+
+	// infoHandler serves the languages and the chapter info of al-fatihah, in the language
+	// asked for when it has info in it, in english otherwise the way quran.com falls back.
+	// Info in a failing language fails.
+	infoHandler := func(languages []Language, withInfo map[int]bool, failing int) http.HandlerFunc {
+		names := map[int]string{}
+		for _, l := range languages {
+			names[l.ID] = l.Name
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v3/options/languages":
+				writeJSON(w, map[string]interface{}{"languages": languages})
+			case "/api/v3/chapters/1/info":
+				id, _ := strconv.Atoi(r.URL.Query().Get("language"))
+				if id == failing {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				name := "english"
+				if withInfo[id] {
+					name = names[id]
+				}
+				writeJSON(w, map[string]interface{}{"chapter_info": ChapterInfo{ChapterID: 1, LanguageName: name}})
+			default:
+				http.NotFound(w, r)
+			}
+		}
+	}
+	languages := []Language{{ID: 174, Name: "urdu"}, {ID: 38, Name: "english"}, {ID: 67, Name: "indonesian"}, {ID: 33, Name: "French"}}
+
+	tests := []struct {
+		name      string
+		languages []Language
+		withInfo  map[int]bool
+		failing   int
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "languages with info ordered by id",
+			languages: languages,
+			withInfo:  map[int]bool{174: true, 38: true, 33: true},
+			want:      []string{"French", "english", "urdu"},
+		},
+		{name: "english fallback only", languages: languages, withInfo: map[int]bool{38: true}, want: []string{"english"}},
+		{name: "no language with info", languages: []Language{{ID: 174, Name: "urdu"}, {ID: 67, Name: "indonesian"}}, want: []string{}},
+		{name: "no languages", languages: []Language{}, want: []string{}},
+		{name: "a language that fails", languages: languages, withInfo: map[int]bool{38: true}, failing: 67, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newHandlerClient(t, infoHandler(tt.languages, tt.withInfo, tt.failing))
+
+			got, err := c.ChapterInfoLanguages(ctx, 1)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
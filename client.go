@@ -3,15 +3,20 @@ package quranc
 import (
 	"bytes"
 	"context"
-	"encoding/gob"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand"
 	"net/http"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jsteenb2/httpc"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 type QuranAPI interface {
@@ -22,21 +27,47 @@ type QuranAPI interface {
 	Chapters(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter, error)
 	Chapter(ctx context.Context, id int, reqOpts ...ReqOptFn) (Chapter, error)
 	ChapterInfo(ctx context.Context, id int, reqOpts ...ReqOptFn) (ChapterInfo, error)
+	ChapterInfoLanguages(ctx context.Context, chapterID int) ([]string, error)
+	ChapterWordCount(ctx context.Context, chapterID int) (int, error)
 	Verses(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, error)
-	Verse(ctx context.Context, chapterID, verseID int) (Verse, error)
+	Verse(ctx context.Context, chapterID, verseID int, reqOpts ...VersesReqOptFn) (Verse, error)
+	VerseTranslations(ctx context.Context, key VerseKey, translationIDs []int) ([]Resource, error)
 	Juzzah(ctx context.Context) ([]Juz, error)
 	VerseTafsir(ctx context.Context, chapterID, verseID int, reqOpts ...VerseTafsirReqOptFn) ([]VerseTafsir, error)
 	Search(ctx context.Context, query SearchRequest) (SearchResponse, error)
 }
 
+// Version is the version of the client, sent in the default user agent.
+const Version = "0.1.0"
+
 // Doer is an interface to abstract the http client out to its basic functionality.
 type Doer interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
 type clientOpt struct {
-	host string
-	doer Doer
+	host      string
+	doer      Doer
+	strictJuz bool
+
+	rateLimitObserver func(RateLimitInfo)
+	sortTranslations  bool
+	searchSize        int
+	searchPage        int
+	pageImageBaseURL  string
+	responseInspector func(*http.Response)
+	concurrency       int
+	requestMiddleware func(*http.Request) error
+	retryAttempts     int
+	retryBaseDelay    time.Duration
+	rateLimit         *rate.Limiter
+	headers           http.Header
+	randSource        rand.Source
+	now               func() time.Time
+	apiVersion        string
+	userAgent         string
+	logger            func(LogRecord)
+	tracerProvider    trace.TracerProvider
 }
 
 // ClientOptFn is an option to set the options of the client constructor.
@@ -50,6 +81,19 @@ func WithHost(host string) ClientOptFn {
 	}
 }
 
+// WithAPIVersion sets the version of the quran.com api the client calls, either "v3" or
+// "v4". The default is "v3". Each method sends the routes and params of the version and
+// decodes its responses into the same types. The chapter, chapter info, juz and verse
+// methods, and TafsirByResource, are served by v4. The others have no v4 port yet and
+// return ErrUnsupportedAPIVersion on a v4 client without sending a request, as does every
+// method of a client set to any other version.
+func WithAPIVersion(v string) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.apiVersion = v
+		return opt
+	}
+}
+
 // WithHTTPClient sets the http client on the quran api client.
 func WithHTTPClient(doer Doer) ClientOptFn {
 	return func(opt clientOpt) clientOpt {
@@ -58,27 +102,328 @@ func WithHTTPClient(doer Doer) ClientOptFn {
 	}
 }
 
+// WithStrictJuzMapping makes Juzzah return an error when a juz's verse mapping
+// contains the same chapter more than once, instead of merging the ranges, or contains
+// a malformed entry, instead of skipping it.
+func WithStrictJuzMapping() ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.strictJuz = true
+		return opt
+	}
+}
+
+// WithRateLimitObserver sets a function that receives the rate limit headers parsed from
+// every response that carries them, so callers can pace themselves.
+func WithRateLimitObserver(fn func(RateLimitInfo)) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.rateLimitObserver = fn
+		return opt
+	}
+}
+
+// WithHeader adds a header sent with every request, i.e. an api key. Headers accumulate
+// over multiple calls, calls with the same key send every value.
+func WithHeader(key, value string) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		headers := make(http.Header, len(opt.headers)+1)
+		for k, vals := range opt.headers {
+			headers[k] = append([]string(nil), vals...)
+		}
+		headers.Add(key, value)
+		opt.headers = headers
+		return opt
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request. It takes precedence
+// over a User-Agent set with WithHeader. The default is quranc-go/ followed by the
+// client's Version.
+func WithUserAgent(ua string) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.userAgent = ua
+		return opt
+	}
+}
+
+// WithBearerToken sends the token as a bearer token in the Authorization header of every
+// request, as the authenticated quran.com api tiers require.
+func WithBearerToken(token string) ClientOptFn {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithRequestMiddleware sets a function that is called with every request just before it
+// is sent, i.e. to add headers or query params. An error from the function aborts the call
+// and is returned from it. Calls served from a cache send no request, so only cache misses
+// reach the function.
+func WithRequestMiddleware(fn func(*http.Request) error) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.requestMiddleware = fn
+		return opt
+	}
+}
+
+// WithRetry retries GET calls that fail with a network error or a 5xx or 429 response,
+// making at most maxAttempts attempts. The delay between attempts starts at baseDelay and
+// doubles with every attempt, with jitter, up to 10 seconds. Waiting between attempts stops
+// when the call's context is done. Responses such as a 404 for a bad chapter id are
+// never retried.
+func WithRetry(maxAttempts int, baseDelay time.Duration) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.retryAttempts = maxAttempts
+		opt.retryBaseDelay = baseDelay
+		return opt
+	}
+}
+
+// WithRateLimit caps the rate of requests the client sends at rps requests a second, with
+// bursts of up to burst requests. The limit is shared by every call made with the client,
+// calls block until they may send their request or their context is done, in which case
+// the context's error is returned. Retries are counted against the limit. An rps of zero
+// or less leaves the client unlimited.
+func WithRateLimit(rps float64, burst int) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.rateLimit = nil
+		if rps > 0 {
+			if burst < 1 {
+				burst = 1
+			}
+			opt.rateLimit = rate.NewLimiter(rate.Limit(rps), burst)
+		}
+		return opt
+	}
+}
+
+// WithLogger sets a function that is called with a record of every request sent, once
+// it completes, i.e. to debug calls against the live api. Every attempt of a retried call
+// is logged. Calls served from a cache send no request and are not logged.
+func WithLogger(fn func(LogRecord)) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.logger = fn
+		return opt
+	}
+}
+
+// WithTracerProvider sets the provider of the tracer the client starts a span with for
+// every api call, named after the method, i.e. quranc.Verses. The span nests under the
+// span of the call's context and carries the chapter id, page and language of the
+// request. Calls served from a cache start no span. No spans are started without a
+// provider.
+func WithTracerProvider(tp trace.TracerProvider) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.tracerProvider = tp
+		return opt
+	}
+}
+
+// WithResponseInspector sets a function that is called with every response received,
+// before its body is decoded, i.e. to read the status code or caching headers. The
+// inspector may read the body, it is given a copy of it.
+func WithResponseInspector(fn func(*http.Response)) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.responseInspector = fn
+		return opt
+	}
+}
+
+// WithDefaultConcurrency sets the number of concurrent requests the batch helpers make
+// when they are called with a concurrency of zero. The default is GOMAXPROCS.
+func WithDefaultConcurrency(n int) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.concurrency = n
+		return opt
+	}
+}
+
+// WithSortedTranslations sorts the translations of every verse returned by their resource
+// id. The api does not guarantee the order of a verse's translations, sorting them keeps
+// the order stable between calls.
+func WithSortedTranslations(sorted bool) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.sortTranslations = sorted
+		return opt
+	}
+}
+
+// WithSearchDefaults sets the size and page used by Search when a SearchRequest leaves
+// them unset. Values set on the SearchRequest take precedence.
+func WithSearchDefaults(size, page int) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.searchSize = size
+		opt.searchPage = page
+		return opt
+	}
+}
+
+// WithRandSource sets the source of randomness RandomVerse picks verses with and retries
+// jitter their delays with, i.e. a seeded source for a deterministic pick and retry
+// timing in tests. The default is seeded with the time read from the client's clock.
+func WithRandSource(src rand.Source) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.randSource = src
+		return opt
+	}
+}
+
+// WithClientClock sets the function the client reads the current time from, to seed its
+// source of randomness, resolve Retry-After dates and rate limit resets, and time the
+// requests it logs and the calls it records stats for. The default is time.Now, it is
+// useful for tests to control time. The cache has its own clock, set with WithClock.
+func WithClientClock(now func() time.Time) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.now = now
+		return opt
+	}
+}
+
+// WithPageImageBaseURL sets the base url PageImageURL builds the urls of mushaf page
+// images from.
+func WithPageImageBaseURL(baseURL string) ClientOptFn {
+	return func(opt clientOpt) clientOpt {
+		opt.pageImageBaseURL = baseURL
+		return opt
+	}
+}
+
 // Client is the API client  that translates the quran.com api into familiar go types.
 type Client struct {
 	c *httpc.Client
+
+	strictJuz        bool
+	sortTranslations bool
+	searchSize       int
+	searchPage       int
+	pageImageBaseURL string
+	apiVersion       string
+	concurrency      int
+	rand             *lockedRand
+	now              func() time.Time
+	tracer           trace.Tracer
 }
 
 // New Constructs a new Client. All default options will be  used if no options are
 // provided to overwrite them. The defaults are:
 //	host: https://quran.com/api
+//	api version: v3
+//	user agent: quranc-go/<Version>
+//	page image base url: https://static.qurancdn.com/images/pages
 func New(opts ...ClientOptFn) *Client {
 	opt := clientOpt{
-		doer: &http.Client{Timeout: 15 * time.Second},
-		host: "https://quran.com/api",
+		doer:             &http.Client{Timeout: 15 * time.Second},
+		host:             "https://quran.com/api",
+		apiVersion:       "v3",
+		pageImageBaseURL: "https://static.qurancdn.com/images/pages",
 	}
 	for _, o := range opts {
 		opt = o(opt)
 	}
+	if opt.now == nil {
+		opt.now = time.Now
+	}
+
+	// the retries jitter their delays from the same source RandomVerse picks from, a
+	// rand.Source is not safe for concurrent use behind two locks.
+	rnd := newLockedRand(opt.randSource, opt.now)
+
+	doer := opt.doer
+	if opt.logger != nil {
+		doer = loggingDoer(doer, opt.logger, opt.now)
+	}
+	if opt.rateLimit != nil {
+		doer = rateLimitDoer(doer, opt.rateLimit)
+	}
+	doer = apiErrorDoer(retryAfterDoer(maintenanceDoer(doer), opt.now))
+	if opt.retryAttempts > 1 {
+		doer = retryDoer(doer, opt.retryAttempts, Backoff{
+			Base:   opt.retryBaseDelay,
+			Max:    maxRetryDelay,
+			Factor: 2,
+			Jitter: 0.2,
+			rand:   rnd,
+		})
+	}
+	if opt.rateLimitObserver != nil {
+		doer = rateLimitObserverDoer(doer, opt.rateLimitObserver, opt.now)
+	}
+	if opt.responseInspector != nil {
+		doer = responseInspectorDoer(doer, opt.responseInspector)
+	}
+	if opt.requestMiddleware != nil {
+		doer = requestMiddlewareDoer(doer, opt.requestMiddleware)
+	}
+	headers := make(http.Header, len(opt.headers)+1)
+	for k, vals := range opt.headers {
+		headers[k] = vals
+	}
+	switch {
+	case opt.userAgent != "":
+		headers.Set("User-Agent", opt.userAgent)
+	case headers.Get("User-Agent") == "":
+		headers.Set("User-Agent", "quranc-go/"+Version)
+	}
+	if len(headers) > 0 {
+		doer = headerDoer(doer, headers)
+	}
+	var tracer trace.Tracer
+	if opt.tracerProvider != nil {
+		tracer = opt.tracerProvider.Tracer(tracerName, trace.WithInstrumentationVersion(Version))
+		doer = spanAttributesDoer(doer)
+	}
 
-	baseURL := opt.host + "/api/v3"
+	baseURL := strings.TrimRight(opt.host, "/") + joinPath("api", opt.apiVersion)
 	return &Client{
-		c: httpc.New(opt.doer, httpc.WithBaseURL(baseURL)),
+		c:                httpc.New(doer, httpc.WithBaseURL(baseURL)),
+		strictJuz:        opt.strictJuz,
+		sortTranslations: opt.sortTranslations,
+		searchSize:       opt.searchSize,
+		searchPage:       opt.searchPage,
+		pageImageBaseURL: strings.TrimRight(opt.pageImageBaseURL, "/"),
+		apiVersion:       opt.apiVersion,
+		concurrency:      opt.concurrency,
+		rand:             rnd,
+		now:              opt.now,
+		tracer:           tracer,
+	}
+}
+
+func (c *Client) defaultConcurrency() int {
+	if c.concurrency > 0 {
+		return c.concurrency
 	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// do sends the request and decodes the json response body into v on success. The
+// method is the name of the api method making the request, used to report stats.
+func (c *Client) do(ctx context.Context, method string, req *httpc.Request, v interface{}) error {
+	start := c.now()
+	ctx, endSpan := c.startSpan(ctx, method)
+	ctx, respErr := withResponseErr(ctx)
+	err := req.
+		Success(httpc.StatusOK()).
+		DecodeJSON(v).
+		Do(ctx)
+	if err != nil && respErr.err != nil {
+		err = respErr.err
+	}
+	if stats := callStatsFromContext(ctx); stats != nil {
+		stats.recordCall(method, c.now().Sub(start), err)
+	}
+	endSpan(err)
+	return err
+}
+
+// joinPath joins the elements into an absolute url path with exactly one slash
+// between each element, regardless of any leading or trailing slashes they carry.
+func joinPath(elems ...string) string {
+	parts := make([]string, 0, len(elems))
+	for _, e := range elems {
+		e = strings.Trim(e, "/")
+		if e == "" {
+			continue
+		}
+		parts = append(parts, e)
+	}
+	return "/" + strings.Join(parts, "/")
 }
 
 // Recitation is a recitation provided from quran.com.
@@ -99,14 +444,18 @@ func (c *Client) Recitations(ctx context.Context, reqOpts ...ReqOptFn) ([]Recita
 	var resp struct {
 		Recitations []Recitation `json:"recitations"`
 	}
-	req := c.c.Get("/options/recitations")
-	err := opt.applyQueryParams(req).
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	path, err := c.route("Recitations", joinPath("options", "recitations"), "")
 	if err != nil {
 		return nil, err
 	}
+	req := c.c.Get(path)
+	err = c.do(ctx, "Recitations", opt.applyQueryParams(req), &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Recitations == nil {
+		resp.Recitations = []Recitation{}
+	}
 	return resp.Recitations, nil
 }
 
@@ -130,14 +479,18 @@ func (c *Client) Translations(ctx context.Context, reqOpts ...ReqOptFn) ([]Trans
 	var resp struct {
 		Translations []Translation `json:"translations"`
 	}
-	req := c.c.Get("/options/translations")
-	err := opt.applyQueryParams(req).
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	path, err := c.route("Translations", joinPath("options", "translations"), "")
 	if err != nil {
 		return nil, err
 	}
+	req := c.c.Get(path)
+	err = c.do(ctx, "Translations", opt.applyQueryParams(req), &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Translations == nil {
+		resp.Translations = []Translation{}
+	}
 
 	sort.Slice(resp.Translations, func(i, j int) bool {
 		return resp.Translations[i].ID < resp.Translations[j].ID
@@ -174,14 +527,18 @@ func (c *Client) Languages(ctx context.Context, reqOpts ...ReqOptFn) ([]Language
 	var resp struct {
 		Languages []Language `json:"languages"`
 	}
-	req := c.c.Get("/options/languages")
-	err := opt.applyQueryParams(req).
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	path, err := c.route("Languages", joinPath("options", "languages"), "")
+	if err != nil {
+		return nil, err
+	}
+	req := c.c.Get(path)
+	err = c.do(ctx, "Languages", opt.applyQueryParams(req), &resp)
 	if err != nil {
 		return nil, err
 	}
+	if resp.Languages == nil {
+		resp.Languages = []Language{}
+	}
 
 	sort.Slice(resp.Languages, func(i, j int) bool {
 		return resp.Languages[i].ID < resp.Languages[j].ID
@@ -210,14 +567,18 @@ func (c *Client) Tafsiraat(ctx context.Context, reqOpts ...ReqOptFn) ([]Tafsir,
 	var resp struct {
 		Tafsirs []Tafsir `json:"tafsirs"`
 	}
-	req := c.c.Get("/options/tafsirs")
-	err := opt.applyQueryParams(req).
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	path, err := c.route("Tafsiraat", joinPath("options", "tafsirs"), "")
+	if err != nil {
+		return nil, err
+	}
+	req := c.c.Get(path)
+	err = c.do(ctx, "Tafsiraat", opt.applyQueryParams(req), &resp)
 	if err != nil {
 		return nil, err
 	}
+	if resp.Tafsirs == nil {
+		resp.Tafsirs = []Tafsir{}
+	}
 
 	sort.Slice(resp.Tafsirs, func(i, j int) bool {
 		return resp.Tafsirs[i].ID < resp.Tafsirs[j].ID
@@ -229,56 +590,62 @@ func (c *Client) Tafsiraat(ctx context.Context, reqOpts ...ReqOptFn) ([]Tafsir,
 // Chapter or surah along with its relevant metadata combine to detail the summary of the
 // chatper as a whole.
 type Chapter struct {
-	ID              int    `json:"id"`
-	ChapterNumber   int    `json:"chapter_number"`
-	BismillahPre    bool   `json:"bismillah_pre"`
-	RevelationOrder int    `json:"revelation_order"`
-	RevelationPlace string `json:"revelation_place"`
-	NameComplex     string `json:"name_complex"`
-	NameArabic      string `json:"name_arabic"`
-	NameSimple      string `json:"name_simple"`
-	VersesCount     int    `json:"verses_count"`
-	Pages           struct {
-		Start int `json:"start"`
-		End   int `json:"end"`
-	} `json:"pages"`
-	TranslatedName TranslatedName `json:"translated_name"`
-}
-
-type apiChapter struct {
 	ID              int            `json:"id"`
 	ChapterNumber   int            `json:"chapter_number"`
 	BismillahPre    bool           `json:"bismillah_pre"`
 	RevelationOrder int            `json:"revelation_order"`
 	RevelationPlace string         `json:"revelation_place"`
-	NameArabic      string         `json:"name_arabic"`
 	NameComplex     string         `json:"name_complex"`
+	NameArabic      string         `json:"name_arabic"`
 	NameSimple      string         `json:"name_simple"`
 	VersesCount     int            `json:"verses_count"`
-	Pages           []int          `json:"pages"`
+	Pages           Pages          `json:"pages"`
 	TranslatedName  TranslatedName `json:"translated_name"`
 }
 
-func apiChapterToChapter(ch apiChapter) Chapter {
-	return Chapter{
-		ID:              ch.ID,
-		ChapterNumber:   ch.ChapterNumber,
-		BismillahPre:    ch.BismillahPre,
-		RevelationOrder: ch.RevelationOrder,
-		RevelationPlace: ch.RevelationPlace,
-		NameArabic:      ch.NameArabic,
-		NameComplex:     ch.NameComplex,
-		NameSimple:      ch.NameSimple,
-		VersesCount:     ch.VersesCount,
-		Pages: struct {
-			Start int `json:"start"`
-			End   int `json:"end"`
-		}{
-			Start: ch.Pages[0],
-			End:   ch.Pages[1],
-		},
-		TranslatedName: ch.TranslatedName,
+// setNumber sets the chapter number from the id, for the v4 api which sends no chapter
+// number.
+func (c *Chapter) setNumber() {
+	if c.ChapterNumber == 0 {
+		c.ChapterNumber = c.ID
+	}
+}
+
+// Pages is the span of mushaf pages a chapter is printed on.
+type Pages struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// UnmarshalJSON decodes the pages from either the v3 api's [start, end] array or from
+// a {"start": start, "end": end} object.
+func (p *Pages) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	if len(b) > 0 && b[0] == '[' {
+		var pages []int
+		if err := json.Unmarshal(b, &pages); err != nil {
+			return fmt.Errorf("decode pages: %s", err)
+		}
+		if len(pages) != 2 {
+			return fmt.Errorf("decode pages: expected [start, end] but got %d pages", len(pages))
+		}
+		p.Start, p.End = pages[0], pages[1]
+		return nil
+	}
+
+	var pages struct {
+		Start int `json:"start"`
+		End   int `json:"end"`
+	}
+	if err := json.Unmarshal(b, &pages); err != nil {
+		return fmt.Errorf("decode pages: %s", err)
 	}
+	p.Start, p.End = pages.Start, pages.End
+	return nil
 }
 
 // Chapters returns the available chapters from quran.com.
@@ -289,27 +656,29 @@ func (c *Client) Chapters(ctx context.Context, reqOpts ...ReqOptFn) ([]Chapter,
 	}
 
 	var resp struct {
-		Chapters []apiChapter `json:"chapters"`
+		Chapters []Chapter `json:"chapters"`
 	}
-	req := c.c.Get("/chapters")
-	err := opt.applyQueryParams(req).
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	path, err := c.route("Chapters", joinPath("chapters"), joinPath("chapters"))
 	if err != nil {
 		return nil, err
 	}
-
-	chapters := make([]Chapter, len(resp.Chapters))
-	for i, ch := range resp.Chapters {
-		chapters[i] = apiChapterToChapter(ch)
+	req := c.c.Get(path)
+	err = c.do(ctx, "Chapters", opt.applyQueryParams(req), &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Chapters == nil {
+		resp.Chapters = []Chapter{}
+	}
+	for i := range resp.Chapters {
+		resp.Chapters[i].setNumber()
 	}
 
-	sort.Slice(chapters, func(i, j int) bool {
-		return chapters[i].ChapterNumber < chapters[j].ChapterNumber
+	sort.Slice(resp.Chapters, func(i, j int) bool {
+		return resp.Chapters[i].ChapterNumber < resp.Chapters[j].ChapterNumber
 	})
 
-	return chapters, nil
+	return resp.Chapters, nil
 }
 
 // Chapters returns the the given chapter by id from quran.com.
@@ -320,18 +689,21 @@ func (c *Client) Chapter(ctx context.Context, id int, reqOpts ...ReqOptFn) (Chap
 	}
 
 	var resp struct {
-		Chapter apiChapter `json:"chapter"`
+		Chapter Chapter `json:"chapter"`
 	}
-	req := c.c.Get("/chapters/" + strconv.Itoa(id))
-	err := opt.applyQueryParams(req).
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	endpoint := joinPath("chapters", strconv.Itoa(id))
+	path, err := c.route("Chapter", endpoint, endpoint)
 	if err != nil {
 		return Chapter{}, err
 	}
+	req := c.c.Get(path)
+	err = c.do(ctx, "Chapter", opt.applyQueryParams(req), &resp)
+	if err != nil {
+		return Chapter{}, err
+	}
+	resp.Chapter.setNumber()
 
-	return apiChapterToChapter(resp.Chapter), nil
+	return resp.Chapter, nil
 }
 
 type ChapterInfo struct {
@@ -351,11 +723,13 @@ func (c *Client) ChapterInfo(ctx context.Context, id int, reqOpts ...ReqOptFn) (
 	var resp struct {
 		ChapterInfo ChapterInfo `json:"chapter_info"`
 	}
-	req := c.c.Get("/chapters/" + strconv.Itoa(id) + "/info")
-	err := opt.applyQueryParams(req).
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	endpoint := joinPath("chapters", strconv.Itoa(id), "info")
+	path, err := c.route("ChapterInfo", endpoint, endpoint)
+	if err != nil {
+		return ChapterInfo{}, err
+	}
+	req := c.c.Get(path)
+	err = c.do(ctx, "ChapterInfo", opt.applyQueryParams(req), &resp)
 	if err != nil {
 		return ChapterInfo{}, err
 	}
@@ -363,25 +737,90 @@ func (c *Client) ChapterInfo(ctx context.Context, id int, reqOpts ...ReqOptFn) (
 	return resp.ChapterInfo, nil
 }
 
+// ChapterInfoLanguages returns the names of the languages the chapter info is available
+// in, ordered by language id. quran.com falls back to english for languages without
+// info, so every language is probed and only those answered in the language asked for
+// are returned.
+func (c *Client) ChapterInfoLanguages(ctx context.Context, chapterID int) ([]string, error) {
+	languages, err := c.Languages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make([]bool, len(languages))
+	_, err = batch(ctx, len(languages), c.defaultConcurrency(), nil, nil, func(ctx context.Context, i int) error {
+		info, err := c.ChapterInfo(ctx, chapterID, LanguageID(languages[i].ID))
+		if err != nil {
+			return fmt.Errorf("chapter info %q: %w", languages[i].Name, err)
+		}
+		available[i] = strings.EqualFold(info.LanguageName, languages[i].Name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for i, l := range languages {
+		if available[i] {
+			names = append(names, l.Name)
+		}
+	}
+	return names, nil
+}
+
 type (
 	ReqOptFn func(opt reqOpt) reqOpt
 
 	reqOpt struct {
-		languageID int
+		LanguageID int
+		Fields     []string
+
+		// noCache is unexported to keep it out of the cache key.
+		noCache bool
 	}
 )
 
 func (o reqOpt) applyQueryParams(r *httpc.Request) *httpc.Request {
-	if o.languageID > 0 {
-		r = r.QueryParam("language", strconv.Itoa(o.languageID))
+	if o.LanguageID > 0 {
+		r = r.QueryParam("language", strconv.Itoa(o.LanguageID))
+	}
+
+	if len(o.Fields) > 0 {
+		r = r.QueryParam("fields", strings.Join(o.Fields, ","))
 	}
 
 	return r
 }
 
+func (o reqOpt) key(ids ...int) ([]byte, error) {
+	o.Fields = append([]string(nil), o.Fields...)
+	sort.Strings(o.Fields)
+	return optionsCacheKey(o, ids...)
+}
+
 func LanguageID(id int) ReqOptFn {
 	return func(opt reqOpt) reqOpt {
-		opt.languageID = id
+		opt.LanguageID = id
+		return opt
+	}
+}
+
+// NoCache makes a cache skip reading the cached value for the call, the value is always
+// fetched from quran.com. The fresh value is still written to the cache.
+func NoCache() ReqOptFn {
+	return func(opt reqOpt) reqOpt {
+		opt.noCache = true
+		return opt
+	}
+}
+
+// ChaptersFields limits the chapter fields returned to those provided, i.e. "id",
+// "name_simple" and "verses_count". This is useful when rendering a list of chapters
+// where the full shape is not needed. Fields that are left out decode to zero values.
+func ChaptersFields(fields ...string) ReqOptFn {
+	return func(opt reqOpt) reqOpt {
+		opt.Fields = fields
 		return opt
 	}
 }
@@ -394,50 +833,93 @@ type Resource struct {
 	ResourceID   int    `json:"resource_id"`
 }
 
+// NormalizedResourceName returns the resource name lower cased with its whitespace
+// collapsed. The api is inconsistent in the casing and spacing of resource names, the
+// normalized name is what resources should be compared and grouped by.
+func (r Resource) NormalizedResourceName() string {
+	return normalizeResourceName(r.ResourceName)
+}
+
+func normalizeResourceName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
 type Verse struct {
-	ID           int    `json:"id"`
-	VerseNumber  int    `json:"verse_number"`
-	ChapterID    int    `json:"chapter_id"`
-	VerseKey     string `json:"verse_key"`
-	TextMadani   string `json:"text_madani"`
-	TextIndopak  string `json:"text_indopak"`
-	TextSimple   string `json:"text_simple"`
-	JuzNumber    int    `json:"juz_number"`
-	HizbNumber   int    `json:"hizb_number"`
-	RubNumber    int    `json:"rub_number"`
-	Sajdah       string `json:"sajdah"`
-	SajdahNumber int    `json:"sajdah_number"`
-	PageNumber   int    `json:"page_number"`
-	Audio        struct {
+	ID                int    `json:"id"`
+	VerseNumber       int    `json:"verse_number"`
+	ChapterID         int    `json:"chapter_id"`
+	VerseKey          string `json:"verse_key"`
+	TextMadani        string `json:"text_madani"`
+	TextIndopak       string `json:"text_indopak"`
+	TextSimple        string `json:"text_simple"`
+	TextUthmani       string `json:"text_uthmani"`
+	TextUthmaniSimple string `json:"text_uthmani_simple"`
+	TextImlaei        string `json:"text_imlaei"`
+	JuzNumber         int    `json:"juz_number"`
+	HizbNumber        int    `json:"hizb_number"`
+	RubNumber         int    `json:"rub_number"`
+	Sajdah            string `json:"sajdah"`
+	SajdahNumber      int    `json:"sajdah_number"`
+	PageNumber        int    `json:"page_number"`
+	Audio             struct {
 		URL      string     `json:"url"`
 		Duration int        `json:"duration"`
 		Segments [][]string `json:"segments"`
 		Format   string     `json:"format"`
 	} `json:"audio"`
-	Translations  []Resource `json:"translations"`
-	MediaContents []struct {
-		URL        string `json:"url"`
-		EmbedText  string `json:"embed_text"`
-		Provider   string `json:"provider"`
-		AuthorName string `json:"author_name"`
-	} `json:"media_contents"`
-	Words []Word `json:"words"`
+	Translations  []Resource     `json:"translations"`
+	MediaContents []MediaContent `json:"media_contents"`
+	Words         []Word         `json:"words"`
+
+	// RelatedVerses are the keys of the verses cross-referenced by the verse, only
+	// included when requested with VersesIncludeRelated.
+	RelatedVerses []string `json:"related_verses"`
+}
+
+// MediaContent is media about a verse, returned for the media resources requested with
+// VersesMedia. The ID is the id of the media resource that produced it.
+type MediaContent struct {
+	ID         int    `json:"resource_id"`
+	URL        string `json:"url"`
+	EmbedText  string `json:"embed_text"`
+	Provider   string `json:"provider"`
+	AuthorName string `json:"author_name"`
+}
+
+// MediaByID returns the verse's media content from the media resource with the id. False
+// is returned when the verse has no content from the resource.
+func (v Verse) MediaByID(id int) (MediaContent, bool) {
+	for _, m := range v.MediaContents {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return MediaContent{}, false
+}
+
+// Related returns the keys of the verses cross-referenced by the verse, i.e. "2:255".
+// It is empty when the verse has no related verses, or they were not requested.
+func (v Verse) Related() []string {
+	return v.RelatedVerses
 }
 
 type Word struct {
-	ID          int    `json:"id"`
-	Position    int    `json:"position"`
-	TextMadani  string `json:"text_madani"`
-	TextIndopak string `json:"text_indopak"`
-	TextSimple  string `json:"text_simple"`
-	VerseKey    string `json:"verse_key"`
-	ClassName   string `json:"class_name"`
-	LineNumber  int    `json:"line_number"`
-	PageNumber  int    `json:"page_number"`
-	Code        string `json:"code"`
-	CodeV3      string `json:"code_v3"`
-	CharType    string `json:"char_type"`
-	Audio       struct {
+	ID                int    `json:"id"`
+	Position          int    `json:"position"`
+	TextMadani        string `json:"text_madani"`
+	TextIndopak       string `json:"text_indopak"`
+	TextSimple        string `json:"text_simple"`
+	TextUthmani       string `json:"text_uthmani"`
+	TextUthmaniSimple string `json:"text_uthmani_simple"`
+	TextImlaei        string `json:"text_imlaei"`
+	VerseKey          string `json:"verse_key"`
+	ClassName         string `json:"class_name"`
+	LineNumber        int    `json:"line_number"`
+	PageNumber        int    `json:"page_number"`
+	Code              string `json:"code"`
+	CodeV3            string `json:"code_v3"`
+	CharType          string `json:"char_type"`
+	Audio             struct {
 		URL string `json:"url"`
 	} `json:"audio"`
 	Translation     Resource `json:"translation"`
@@ -447,17 +929,31 @@ type Word struct {
 type (
 	VersesReqOptFn func(opts versesReqOpt) versesReqOpt
 
+	// versesReqOpt is the options of the verse routes. Every option sent to quran.com is
+	// an exported field so it is part of the cache key, see optionsCacheKey.
 	versesReqOpt struct {
 		Language   string
 		Recitation int
 		TextType   string
+		Mushaf     int
 
 		Page   int
 		Limit  int
 		Offset int
 
-		Media        []int
-		Translations []int
+		Media             []int
+		Translations      []int
+		TranslationFields []string
+		WordFields        []string
+
+		ExcludeWords   bool
+		ExcludeAudio   bool
+		IncludeRelated bool
+
+		// noCache and arrivalOrder are unexported to keep them out of the gob encoded
+		// cache key.
+		noCache      bool
+		arrivalOrder bool
 	}
 )
 
@@ -466,7 +962,7 @@ func (v versesReqOpt) queryParams(r *httpc.Request) *httpc.Request {
 		r = r.QueryParam("language", v.Language)
 	}
 
-	if v.Recitation > 0 {
+	if v.Recitation > 0 && !v.ExcludeAudio {
 		r = r.QueryParam("recitation", strconv.Itoa(v.Recitation))
 	}
 
@@ -474,6 +970,10 @@ func (v versesReqOpt) queryParams(r *httpc.Request) *httpc.Request {
 		r = r.QueryParam("text_type", v.TextType)
 	}
 
+	if v.Mushaf > 0 {
+		r = r.QueryParam("mushaf", strconv.Itoa(v.Mushaf))
+	}
+
 	if v.Page > 0 {
 		r = r.QueryParam("page", strconv.Itoa(v.Page))
 	}
@@ -482,6 +982,10 @@ func (v versesReqOpt) queryParams(r *httpc.Request) *httpc.Request {
 		r = r.QueryParam("limit", strconv.Itoa(v.Limit))
 	}
 
+	if v.Offset > 0 {
+		r = r.QueryParam("offset", strconv.Itoa(v.Offset))
+	}
+
 	for _, media := range v.Media {
 		r = r.QueryParam("media[]", strconv.Itoa(media))
 	}
@@ -490,24 +994,54 @@ func (v versesReqOpt) queryParams(r *httpc.Request) *httpc.Request {
 		r = r.QueryParam("translations[]", strconv.Itoa(translation))
 	}
 
+	if len(v.TranslationFields) > 0 {
+		r = r.QueryParam("translation_fields", strings.Join(v.TranslationFields, ","))
+	}
+
+	if v.ExcludeWords {
+		r = r.QueryParam("words", "false")
+	} else if len(v.WordFields) > 0 {
+		r = r.QueryParam("word_fields", strings.Join(v.WordFields, ","))
+	}
+	if v.IncludeRelated {
+		r = r.QueryParam("related_verses", "true")
+	}
+
 	return r
 }
 
+func (v versesReqOpt) validate() error {
+	for _, media := range v.Media {
+		if media < 1 {
+			return fmt.Errorf("%w: %d", ErrInvalidMedia, media)
+		}
+	}
+	return nil
+}
+
 func (v versesReqOpt) key(chapterID int) ([]byte, error) {
+	v.Media = append([]int(nil), v.Media...)
 	sort.Ints(v.Media)
+	v.Translations = append([]int(nil), v.Translations...)
 	sort.Ints(v.Translations)
-
-	input := struct {
-		VerseReqOpts versesReqOpt
-		ChapterID    int
-	}{
-		VerseReqOpts: v,
-		ChapterID:    chapterID,
+	v.TranslationFields = append([]string(nil), v.TranslationFields...)
+	sort.Strings(v.TranslationFields)
+	v.WordFields = append([]string(nil), v.WordFields...)
+	sort.Strings(v.WordFields)
+	if v.ExcludeAudio {
+		// the recitation is not sent without audio, so it does not change the response.
+		v.Recitation = 0
 	}
 
-	var buf bytes.Buffer
-	err := gob.NewEncoder(&buf).Encode(input)
-	return buf.Bytes(), err
+	return optionsCacheKey(v, chapterID)
+}
+
+// pinKey returns the key of the chapter's verses with the options, regardless of the
+// page, limit and offset. It identifies every page of a chapter fetched with the same
+// options.
+func (v versesReqOpt) pinKey(chapterID int) ([]byte, error) {
+	v.Page, v.Limit, v.Offset = 0, 0, 0
+	return v.key(chapterID)
 }
 
 func VersesLanguage(isoCode string) VersesReqOptFn {
@@ -531,6 +1065,15 @@ func VersesTextType(textType string) VersesReqOptFn {
 	}
 }
 
+// VersesMushaf sets the quran.com mushaf id the verses are laid out in, which changes
+// the pages and glyph codes of the verses and their words.
+func VersesMushaf(id int) VersesReqOptFn {
+	return func(opts versesReqOpt) versesReqOpt {
+		opts.Mushaf = id
+		return opts
+	}
+}
+
 func VersesLimit(i int) VersesReqOptFn {
 	return func(opts versesReqOpt) versesReqOpt {
 		opts.Limit = i
@@ -552,6 +1095,8 @@ func VersesPage(i int) VersesReqOptFn {
 	}
 }
 
+// VersesMedia sets the media content to include with the verses. Media ids must be
+// positive, otherwise the request fails with ErrInvalidMedia before it is sent.
 func VersesMedia(media []int) VersesReqOptFn {
 	return func(opts versesReqOpt) versesReqOpt {
 		opts.Media = media
@@ -566,52 +1111,349 @@ func VersesTranslations(translations []int) VersesReqOptFn {
 	}
 }
 
+// VersesTranslationFields limits the fields of each translation returned to those provided,
+// i.e. just "text", leaving out footnotes and resource metadata. Fields that are left out
+// decode to zero values.
+func VersesTranslationFields(fields ...string) VersesReqOptFn {
+	return func(opts versesReqOpt) versesReqOpt {
+		opts.TranslationFields = fields
+		return opts
+	}
+}
+
+// VersesWordFields adds the fields provided to each word returned, i.e. "translation"
+// and "transliteration" to populate Word.Translation and Word.Transliteration. It has no
+// effect when words are excluded with VersesIncludeWords.
+func VersesWordFields(fields ...string) VersesReqOptFn {
+	return func(opts versesReqOpt) versesReqOpt {
+		opts.WordFields = fields
+		return opts
+	}
+}
+
+// VersesNoCache makes a cache skip reading the cached verses for the call, the verses are
+// always fetched from quran.com. The fresh verses are still written to the cache.
+func VersesNoCache() VersesReqOptFn {
+	return func(opts versesReqOpt) versesReqOpt {
+		opts.noCache = true
+		return opts
+	}
+}
+
+// VersesIncludeWords sets whether the words of each verse are included in the response.
+// Words are included by default, when they are not needed, excluding them cuts the
+// payload down considerably and leaves Verse.Words empty. Verse.WordsOnly and
+// Verse.EndMarker fall back to the verse-level text for verses fetched without words.
+func VersesIncludeWords(include bool) VersesReqOptFn {
+	return func(opts versesReqOpt) versesReqOpt {
+		opts.ExcludeWords = !include
+		return opts
+	}
+}
+
+// VersesIncludeAudio sets whether the audio of the recitation set with VersesRecitation is
+// included in the response. The api attaches audio to every verse when a recitation is
+// requested, excluding it leaves Verse.Audio empty for text only fetches.
+func VersesIncludeAudio(include bool) VersesReqOptFn {
+	return func(opts versesReqOpt) versesReqOpt {
+		opts.ExcludeAudio = !include
+		return opts
+	}
+}
+
+// VersesIncludeRelated sets whether the keys of the verses cross-referenced by each verse
+// are included in the response, decoded into Verse.RelatedVerses.
+func VersesIncludeRelated(include bool) VersesReqOptFn {
+	return func(opts versesReqOpt) versesReqOpt {
+		opts.IncludeRelated = include
+		return opts
+	}
+}
+
 func (c *Client) Verses(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	verses, _, err := c.versesPage(ctx, chapterID, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return verses, nil
+}
+
+// VersePage is a page of a chapter's verses along with its pagination meta. NextPage is
+// zero on the last page and PrevPage is nil on the first.
+type VersePage struct {
+	Verses      []Verse
+	CurrentPage int
+	NextPage    int
+	PrevPage    *int
+	TotalPages  int
+	TotalCount  int
+}
+
+// VersesPage returns a page of the chapter's verses as Verses does, along with the
+// pagination meta quran.com sends with it, i.e. to build a paging ui.
+func (c *Client) VersesPage(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) (VersePage, error) {
+	verses, meta, err := c.versesPage(ctx, chapterID, reqOpts...)
+	if err != nil {
+		return VersePage{}, err
+	}
+
+	return VersePage{
+		Verses:      verses,
+		CurrentPage: meta.CurrentPage,
+		NextPage:    meta.NextPage,
+		PrevPage:    meta.PrevPage,
+		TotalPages:  meta.TotalPages,
+		TotalCount:  meta.TotalCount,
+	}, nil
+}
+
+// versesMeta is the pagination meta of a page of verses. Some verse routes return no meta
+// block, or a null next page on the last page, either decodes to a zero next page. The
+// null previous page of the first page decodes to nil.
+type versesMeta struct {
+	CurrentPage int  `json:"current_page"`
+	NextPage    int  `json:"next_page"`
+	PrevPage    *int `json:"prev_page"`
+	TotalPages  int  `json:"total_pages"`
+	TotalCount  int  `json:"total_count"`
+}
+
+// versesPagination is the pagination block of the v4 api, sent in place of the v3 meta.
+type versesPagination struct {
+	CurrentPage  int `json:"current_page"`
+	NextPage     int `json:"next_page"`
+	TotalPages   int `json:"total_pages"`
+	TotalRecords int `json:"total_records"`
+}
+
+func (p versesPagination) meta() versesMeta {
+	m := versesMeta{
+		CurrentPage: p.CurrentPage,
+		NextPage:    p.NextPage,
+		TotalPages:  p.TotalPages,
+		TotalCount:  p.TotalRecords,
+	}
+	if p.CurrentPage > 1 {
+		prev := p.CurrentPage - 1
+		m.PrevPage = &prev
+	}
+	return m
+}
+
+// lastPage reports whether the page is the last one, an absent meta is treated as a
+// single page result.
+func (m versesMeta) lastPage(page int) bool {
+	return m.NextPage <= page
+}
+
+// versesPage returns a page of the chapter's verses along with the pagination meta.
+func (c *Client) versesPage(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, versesMeta, error) {
 	var opts versesReqOpt
 	for _, optFn := range reqOpts {
 		opts = optFn(opts)
 	}
+	if err := opts.validate(); err != nil {
+		return nil, versesMeta{}, err
+	}
 
-	req := c.c.Get("/chapters/" + strconv.Itoa(chapterID) + "/verses")
-	req = opts.queryParams(req)
-
-	var resp struct {
-		Verses []Verse `json:"verses"`
-		Meta   struct {
-			CurrentPage int         `json:"current_page"`
-			NextPage    int         `json:"next_page"`
-			PrevPage    interface{} `json:"prev_page"`
-			TotalPages  int         `json:"total_pages"`
-			TotalCount  int         `json:"total_count"`
-		} `json:"meta"`
+	path, err := c.route("Verses",
+		joinPath("chapters", strconv.Itoa(chapterID), "verses"),
+		joinPath("verses", "by_chapter", strconv.Itoa(chapterID)),
+	)
+	if err != nil {
+		return nil, versesMeta{}, err
 	}
-	err := req.
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	req, err := c.versesQuery(c.c.Get(path), opts)
 	if err != nil {
-		return nil, err
+		return nil, versesMeta{}, err
+	}
+
+	verses, meta, err := c.doVerses(ctx, "Verses", req)
+	if err != nil {
+		return nil, versesMeta{}, err
 	}
 
-	return resp.Verses, nil
+	c.prepareVerses(verses)
+
+	return verses, meta, nil
 }
 
-// TODO: make github issue to fix the route in api docs for this route is routed incorrectly
-func (c *Client) Verse(ctx context.Context, chapterID, verseID int) (Verse, error) {
+// doVerses sends the request for a page of verses and decodes the verses from the
+// response of the api version the client calls, along with the pagination meta.
+func (c *Client) doVerses(ctx context.Context, method string, req *httpc.Request) ([]Verse, versesMeta, error) {
+	if c.apiVersion == apiV4 {
+		var resp struct {
+			Verses     []apiVerseV4     `json:"verses"`
+			Pagination versesPagination `json:"pagination"`
+		}
+		if err := c.do(ctx, method, req, &resp); err != nil {
+			return nil, versesMeta{}, err
+		}
+
+		verses := make([]Verse, 0, len(resp.Verses))
+		for _, v := range resp.Verses {
+			verses = append(verses, v.verse())
+		}
+		return verses, resp.Pagination.meta(), nil
+	}
+
+	var resp struct {
+		Verses []Verse    `json:"verses"`
+		Meta   versesMeta `json:"meta"`
+	}
+	if err := c.do(ctx, method, req, &resp); err != nil {
+		return nil, versesMeta{}, err
+	}
+	if resp.Verses == nil {
+		resp.Verses = []Verse{}
+	}
+	return resp.Verses, resp.Meta, nil
+}
+
+// doVerse sends the request for a single verse and decodes it from the response of the
+// api version the client calls.
+func (c *Client) doVerse(ctx context.Context, method string, req *httpc.Request) (Verse, error) {
+	if c.apiVersion == apiV4 {
+		var resp struct {
+			Verse apiVerseV4 `json:"verse"`
+		}
+		if err := c.do(ctx, method, req, &resp); err != nil {
+			return Verse{}, err
+		}
+		return resp.Verse.verse(), nil
+	}
+
 	var resp struct {
 		Verse Verse `json:"verse"`
 	}
+	if err := c.do(ctx, method, req, &resp); err != nil {
+		return Verse{}, err
+	}
+	return resp.Verse, nil
+}
 
-	endpoint := "/chapters/" + strconv.Itoa(chapterID) + "/verses/" + strconv.Itoa(verseID)
-	err := c.c.Get(endpoint).
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+// TODO: make github issue to fix the route in api docs for this route is routed incorrectly
+func (c *Client) Verse(ctx context.Context, chapterID, verseID int, reqOpts ...VersesReqOptFn) (Verse, error) {
+	var opts versesReqOpt
+	for _, optFn := range reqOpts {
+		opts = optFn(opts)
+	}
+	if err := opts.validate(); err != nil {
+		return Verse{}, err
+	}
+
+	path, err := c.route("Verse",
+		joinPath("chapters", strconv.Itoa(chapterID), "verses", strconv.Itoa(verseID)),
+		joinPath("verses", "by_key", verseKey(chapterID, verseID)),
+	)
+	if err != nil {
+		return Verse{}, err
+	}
+	req, err := c.versesQuery(c.c.Get(path), opts)
 	if err != nil {
 		return Verse{}, err
 	}
 
-	return resp.Verse, nil
+	verse, err := c.doVerse(ctx, "Verse", req)
+	if err != nil {
+		return Verse{}, err
+	}
+
+	c.prepareVerses([]Verse{verse})
+
+	return verse, nil
+}
+
+// VerseTranslations returns the translations of the verse with the translation ids
+// provided, sorted by resource id. Only the translations are sent, making it much lighter
+// than fetching the verse when only its translated text is needed.
+func (c *Client) VerseTranslations(ctx context.Context, key VerseKey, translationIDs []int) ([]Resource, error) {
+	if _, err := ParseVerseKey(key.String()); err != nil {
+		return nil, err
+	}
+
+	path, err := c.route("VerseTranslations",
+		joinPath("chapters", strconv.Itoa(key.Chapter), "verses", strconv.Itoa(key.Verse), "translations"),
+		"",
+	)
+	if err != nil {
+		return nil, err
+	}
+	req := c.c.Get(path)
+	for _, id := range translationIDs {
+		req = req.QueryParam("translations[]", strconv.Itoa(id))
+	}
+
+	var resp struct {
+		Translations []Resource `json:"translations"`
+	}
+	err = c.do(ctx, "VerseTranslations", req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Translations == nil {
+		resp.Translations = []Resource{}
+	}
+
+	sort.SliceStable(resp.Translations, func(i, j int) bool {
+		return resp.Translations[i].ResourceID < resp.Translations[j].ResourceID
+	})
+	return resp.Translations, nil
+}
+
+// prepareVerses applies the client's post processing options to the decoded verses.
+func (c *Client) prepareVerses(verses []Verse) {
+	if !c.sortTranslations {
+		return
+	}
+
+	for _, v := range verses {
+		sort.SliceStable(v.Translations, func(i, j int) bool {
+			return v.Translations[i].ResourceID < v.Translations[j].ResourceID
+		})
+	}
+}
+
+// VersesByPage returns the verses laid out on the given mushaf page, i.e. to render a page
+// of the mushaf, ordered by their keys with the words of each verse ordered by position.
+// The page must be between 1 and 604.
+func (c *Client) VersesByPage(ctx context.Context, page int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	if err := validatePage(page); err != nil {
+		return nil, err
+	}
+
+	var opts versesReqOpt
+	for _, optFn := range reqOpts {
+		opts = optFn(opts)
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := joinPath("verses", "by_page", strconv.Itoa(page))
+	path, err := c.route("VersesByPage", endpoint, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.versesQuery(c.c.Get(path), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	verses, _, err := c.doVerses(ctx, "VersesByPage", req)
+	if err != nil {
+		return nil, err
+	}
+
+	sortVersesByKey(verses)
+	for _, v := range verses {
+		sort.SliceStable(v.Words, func(i, j int) bool {
+			return v.Words[i].Position < v.Words[j].Position
+		})
+	}
+	c.prepareVerses(verses)
+
+	return verses, nil
 }
 
 type Juz struct {
@@ -632,38 +1474,76 @@ type apiJuz struct {
 	VerseMapping map[string]string `json:"verse_mapping"`
 }
 
-func convertAPIJuzToJuz(j apiJuz) Juz {
-	strToInt := func(s string) int {
-		i, err := strconv.Atoi(s)
-		if err != nil {
-			return -1
-		}
-		return i
-	}
-
+// convertAPIJuzToJuz converts the api's juz into a Juz. The verse mapping should
+// never contain the same chapter twice, but when it does (i.e. "2" and "02") the
+// ranges are merged into a single mapping spanning both. A malformed entry, one whose
+// chapter is not a number or whose verses are not a range of start-end numbers, is
+// skipped. When strict is set, a duplicated chapter or a malformed entry is an error
+// instead.
+func convertAPIJuzToJuz(j apiJuz, strict bool) (Juz, error) {
 	juz := Juz{
 		ID:        j.ID,
 		JuzNumber: j.JuzNumber,
 	}
 
+	seen := make(map[int]int)
 	for chapterID, ayaat := range j.VerseMapping {
-		startEnds := strings.Split(ayaat, "-")
-		if len(startEnds) != 2 {
+		mapping, ok := parseJuzMapping(chapterID, ayaat)
+		if !ok {
+			if strict {
+				return Juz{}, fmt.Errorf("juz %d: malformed verse mapping %q: %q", j.JuzNumber, chapterID, ayaat)
+			}
 			continue
 		}
 
-		juz.VerseMapping = append(juz.VerseMapping, JuzMapping{
-			ChapterID:  strToInt(chapterID),
-			StartVerse: strToInt(startEnds[0]),
-			EndVerse:   strToInt(startEnds[1]),
-		})
+		idx, ok := seen[mapping.ChapterID]
+		if !ok {
+			seen[mapping.ChapterID] = len(juz.VerseMapping)
+			juz.VerseMapping = append(juz.VerseMapping, mapping)
+			continue
+		}
+		if strict {
+			return Juz{}, fmt.Errorf("juz %d: duplicate verse mapping for chapter %d", j.JuzNumber, mapping.ChapterID)
+		}
+
+		existing := &juz.VerseMapping[idx]
+		if mapping.StartVerse < existing.StartVerse {
+			existing.StartVerse = mapping.StartVerse
+		}
+		if mapping.EndVerse > existing.EndVerse {
+			existing.EndVerse = mapping.EndVerse
+		}
 	}
 
 	sort.Slice(juz.VerseMapping, func(i, j int) bool {
 		return juz.VerseMapping[i].ChapterID < juz.VerseMapping[j].ChapterID
 	})
 
-	return juz
+	return juz, nil
+}
+
+// parseJuzMapping parses an entry of the api's verse mapping, i.e. "2": "1-141". False is
+// returned when the chapter or verses are not positive numbers, or the range is reversed.
+func parseJuzMapping(chapterID, ayaat string) (JuzMapping, bool) {
+	startEnd := strings.Split(ayaat, "-")
+	if len(startEnd) != 2 {
+		return JuzMapping{}, false
+	}
+
+	var (
+		nums [3]int
+		err  error
+	)
+	for i, s := range []string{chapterID, startEnd[0], startEnd[1]} {
+		nums[i], err = strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || nums[i] < 1 {
+			return JuzMapping{}, false
+		}
+	}
+	if nums[1] > nums[2] {
+		return JuzMapping{}, false
+	}
+	return JuzMapping{ChapterID: nums[0], StartVerse: nums[1], EndVerse: nums[2]}, true
 }
 
 func (c *Client) Juzzah(ctx context.Context) ([]Juz, error) {
@@ -674,17 +1554,22 @@ func (c *Client) Juzzah(ctx context.Context) ([]Juz, error) {
 			VerseMapping map[string]string `json:"verse_mapping"`
 		} `json:"juzs"`
 	}
-	err := c.c.Get("/juzs").
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	path, err := c.route("Juzzah", joinPath("juzs"), joinPath("juzs"))
+	if err != nil {
+		return nil, err
+	}
+	err = c.do(ctx, "Juzzah", c.c.Get(path), &resp)
 	if err != nil {
 		return nil, err
 	}
 
 	juzzah := make([]Juz, len(resp.Juzzah))
 	for i, aj := range resp.Juzzah {
-		juzzah[i] = convertAPIJuzToJuz(aj)
+		juz, err := convertAPIJuzToJuz(aj, c.strictJuz)
+		if err != nil {
+			return nil, err
+		}
+		juzzah[i] = juz
 	}
 
 	return juzzah, nil
@@ -697,9 +1582,47 @@ type VerseTafsir struct {
 	LanguageName string `json:"language_name"`
 	ResourceName string `json:"resource_name"`
 
-	// VerseKey  outlined int he api response, but there is nothing that speaks
-	// to it in the api documentation... hopefully someone can fill in the gap here
-	VerseKey interface{} `json:"verse_key"`
+	// VerseKey is the key of the verse, i.e. "2:255". It is undocumented, so it is
+	// decoded leniently, a missing or null key is left empty.
+	VerseKey string `json:"verse_key"`
+}
+
+// UnmarshalJSON decodes the tafsir, accepting a verse key sent as a string, a number, an
+// object of the verse's chapter and verse numbers, or null.
+func (v *VerseTafsir) UnmarshalJSON(b []byte) error {
+	type verseTafsir VerseTafsir
+	var raw struct {
+		verseTafsir
+		VerseKey interface{} `json:"verse_key"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*v = VerseTafsir(raw.verseTafsir)
+	switch key := raw.VerseKey.(type) {
+	case string:
+		v.VerseKey = key
+	case float64:
+		v.VerseKey = strconv.FormatFloat(key, 'f', -1, 64)
+	case map[string]interface{}:
+		chapter, _ := firstNumber(key, "chapter", "chapter_id", "chapter_number")
+		verse, _ := firstNumber(key, "verse", "verse_number")
+		if chapter > 0 && verse > 0 {
+			v.VerseKey = verseKey(int(chapter), int(verse))
+		}
+	}
+	return nil
+}
+
+// firstNumber returns the first of the fields of the json object that is a number.
+func firstNumber(obj map[string]interface{}, fields ...string) (float64, bool) {
+	for _, f := range fields {
+		if n, ok := obj[f].(float64); ok {
+			return n, true
+		}
+	}
+	return 0, false
 }
 
 type (
@@ -710,6 +1633,10 @@ type (
 	}
 )
 
+func (v verseTafsirReqOpts) key(chapterID, verseID int) ([]byte, error) {
+	return optionsCacheKey(v, chapterID, verseID)
+}
+
 func TafsirID(id int) VerseTafsirReqOptFn {
 	return func(opts verseTafsirReqOpts) verseTafsirReqOpts {
 		opts.Tafsir = strconv.Itoa(id)
@@ -723,8 +1650,14 @@ func (c *Client) VerseTafsir(ctx context.Context, chapterID, verseID int, reqOpt
 		opts = optFn(opts)
 	}
 
-	endpoint := "/chapters/" + strconv.Itoa(chapterID) + "/verses/" + strconv.Itoa(verseID) + "/tafsirs"
-	req := c.c.Get(endpoint)
+	path, err := c.route("VerseTafsir",
+		joinPath("chapters", strconv.Itoa(chapterID), "verses", strconv.Itoa(verseID), "tafsirs"),
+		"",
+	)
+	if err != nil {
+		return nil, err
+	}
+	req := c.c.Get(path)
 
 	if opts.Tafsir != "" {
 		req = req.QueryParam("tafsirs", opts.Tafsir)
@@ -733,13 +1666,13 @@ func (c *Client) VerseTafsir(ctx context.Context, chapterID, verseID int, reqOpt
 	var resp struct {
 		Tafsirs []VerseTafsir `json:"tafsirs"`
 	}
-	err := req.
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	err = c.do(ctx, "VerseTafsir", req, &resp)
 	if err != nil {
 		return nil, err
 	}
+	if resp.Tafsirs == nil {
+		resp.Tafsirs = []VerseTafsir{}
+	}
 
 	return resp.Tafsirs, nil
 }
@@ -762,6 +1695,9 @@ type (
 		Results     []SearchVerse `json:"results"`
 	}
 
+	// SearchVerse is a verse matching a search. Depending on the options the words and
+	// translations may be left out of a result, they are empty rather than nil when they
+	// are, and TextMadani should be used to render the verse.
 	SearchVerse struct {
 		ID           int        `json:"id"`
 		VerseNumber  int        `json:"verse_number"`
@@ -777,8 +1713,18 @@ func (c *Client) Search(ctx context.Context, query SearchRequest) (SearchRespons
 	if query.Query == "" {
 		return SearchResponse{}, errors.New("no query param provided")
 	}
+	if query.Page <= 0 {
+		query.Page = c.searchPage
+	}
+	if query.Size <= 0 {
+		query.Size = c.searchSize
+	}
 
-	req := c.c.Get("/search").
+	path, err := c.route("Search", joinPath("search"), "")
+	if err != nil {
+		return SearchResponse{}, err
+	}
+	req := c.c.Get(path).
 		QueryParam("q", query.Query)
 	if query.Language != "" {
 		req = req.QueryParam("language", query.Language)
@@ -791,13 +1737,11 @@ func (c *Client) Search(ctx context.Context, query SearchRequest) (SearchRespons
 	}
 
 	var resp SearchResponse
-	err := req.
-		Success(httpc.StatusOK()).
-		DecodeJSON(&resp).
-		Do(ctx)
+	err = c.do(ctx, "Search", req, &resp)
 	if err != nil {
 		return SearchResponse{}, err
 	}
+	resp.normalize()
 
 	return resp, nil
 }
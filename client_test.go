@@ -0,0 +1,515 @@
+package quranc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestClient returns a Client of a server that responds to every request with the
+// body provided.
+func newTestClient(t *testing.T, body string, opts ...ClientOptFn) *Client {
+	t.Helper()
+
+	return newHandlerClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}), opts...)
+}
+
+// newHandlerClient returns a Client of a server that serves every request with the handler.
+func newHandlerClient(t *testing.T, h http.Handler, opts ...ClientOptFn) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	return New(append([]ClientOptFn{WithHost(srv.URL)}, opts...)...)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// versesStub is a stub of the v3 quran.com api serving the chapters provided, each chapter
+// by its number, the verses of those chapters paged the way quran.com pages them, and each
+// verse by its number. It counts the requests made to every path. When intercept is set it is called with every request first, and
+// serves the request in place of the stub when it returns true.
+type versesStub struct {
+	chapters  []Chapter
+	intercept func(w http.ResponseWriter, r *http.Request) bool
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (s *versesStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if s.requests == nil {
+		s.requests = make(map[string]int)
+	}
+	s.requests[r.URL.Path]++
+	s.mu.Unlock()
+
+	if s.intercept != nil && s.intercept(w, r) {
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v3/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "chapters":
+		writeJSON(w, map[string]interface{}{"chapters": s.chapters})
+	case len(parts) == 2 && parts[0] == "chapters":
+		id, _ := strconv.Atoi(parts[1])
+		for _, ch := range s.chapters {
+			if ch.ChapterNumber == id {
+				writeJSON(w, map[string]interface{}{"chapter": ch})
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case len(parts) == 3 && parts[0] == "chapters" && parts[2] == "verses":
+		id, _ := strconv.Atoi(parts[1])
+		for _, ch := range s.chapters {
+			if ch.ChapterNumber == id {
+				writeJSON(w, stubVersesPage(r, ch))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case len(parts) == 4 && parts[0] == "chapters" && parts[2] == "verses":
+		id, _ := strconv.Atoi(parts[1])
+		v, _ := strconv.Atoi(parts[3])
+		for _, ch := range s.chapters {
+			if ch.ChapterNumber == id && v >= 1 && v <= ch.VersesCount {
+				writeJSON(w, map[string]interface{}{"verse": stubVerse(ch, v)})
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// requestCount returns the number of requests made to the path.
+func (s *versesStub) requestCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// totalRequests returns the number of requests made to every path.
+func (s *versesStub) totalRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	for _, c := range s.requests {
+		n += c
+	}
+	return n
+}
+
+// stubVerse returns the verse of the chapter with the number, its id is unique across
+// chapters.
+func stubVerse(ch Chapter, v int) Verse {
+	return Verse{
+		ID:          ch.ChapterNumber*1000 + v,
+		ChapterID:   ch.ChapterNumber,
+		VerseNumber: v,
+		VerseKey:    verseKey(ch.ChapterNumber, v),
+	}
+}
+
+// stubVersesPage returns the v3 response of the page of the chapter's verses asked for by
+// the page, limit and offset of the request.
+func stubVersesPage(r *http.Request, ch Chapter) interface{} {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit < 1 {
+		limit = defaultVersesLimit
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	verses := []Verse{}
+	for v := (page-1)*limit + offset + 1; v <= ch.VersesCount && len(verses) < limit; v++ {
+		verses = append(verses, stubVerse(ch, v))
+	}
+
+	totalPages := (ch.VersesCount - offset + limit - 1) / limit
+	meta := map[string]interface{}{
+		"current_page": page,
+		"next_page":    nil,
+		"prev_page":    nil,
+		"total_pages":  totalPages,
+		"total_count":  ch.VersesCount,
+	}
+	if page < totalPages {
+		meta["next_page"] = page + 1
+	}
+	if page > 1 {
+		meta["prev_page"] = page - 1
+	}
+	return map[string]interface{}{"verses": verses, "meta": meta}
+}
+
+func TestClient_emptyLists(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, `{}`)
+
+	tests := []struct {
+		name string
+		call func() (interface{}, error)
+	}{
+		{name: "Recitations", call: func() (interface{}, error) { return c.Recitations(ctx) }},
+		{name: "Translations", call: func() (interface{}, error) { return c.Translations(ctx) }},
+		{name: "Languages", call: func() (interface{}, error) { return c.Languages(ctx) }},
+		{name: "Tafsiraat", call: func() (interface{}, error) { return c.Tafsiraat(ctx) }},
+		{name: "Chapters", call: func() (interface{}, error) { return c.Chapters(ctx) }},
+		{name: "Verses", call: func() (interface{}, error) { return c.Verses(ctx, 1) }},
+		{name: "VersesByPage", call: func() (interface{}, error) { return c.VersesByPage(ctx, 1) }},
+		{name: "VersesByJuz", call: func() (interface{}, error) { return c.VersesByJuz(ctx, 1) }},
+		{name: "VerseTafsir", call: func() (interface{}, error) { return c.VerseTafsir(ctx, 1, 1) }},
+		{name: "VerseTranslations", call: func() (interface{}, error) {
+			return c.VerseTranslations(ctx, VerseKey{Chapter: 1, Verse: 1}, nil)
+		}},
+		{name: "Search results", call: func() (interface{}, error) {
+			resp, err := c.Search(ctx, SearchRequest{Query: "mercy"})
+			return resp.Results, err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.call()
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := reflect.ValueOf(got)
+			if v.IsNil() || v.Len() != 0 {
+				t.Errorf("got %#v, want an empty non-nil slice", got)
+			}
+		})
+	}
+}
+
+func versesKey(t *testing.T, chapterID int, reqOpts ...VersesReqOptFn) []byte {
+	t.Helper()
+
+	var opts versesReqOpt
+	for _, o := range reqOpts {
+		opts = o(opts)
+	}
+	key, err := opts.key(chapterID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestVersesReqOpt_key(t *testing.T) {
+	t.Run("every option that changes the response has a distinct key", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			reqOpts []VersesReqOptFn
+		}{
+			{name: "no options"},
+			{name: "language", reqOpts: []VersesReqOptFn{VersesLanguage("ur")}},
+			{name: "recitation", reqOpts: []VersesReqOptFn{VersesRecitation(7)}},
+			{name: "text type", reqOpts: []VersesReqOptFn{VersesTextType(TextTypeImlaei)}},
+			{name: "mushaf", reqOpts: []VersesReqOptFn{VersesMushaf(3)}},
+			{name: "limit", reqOpts: []VersesReqOptFn{VersesLimit(20)}},
+			{name: "offset", reqOpts: []VersesReqOptFn{VersesOffset(20)}},
+			{name: "page", reqOpts: []VersesReqOptFn{VersesPage(2)}},
+			{name: "media", reqOpts: []VersesReqOptFn{VersesMedia([]int{1})}},
+			{name: "translations", reqOpts: []VersesReqOptFn{VersesTranslations([]int{20})}},
+			{name: "translation fields", reqOpts: []VersesReqOptFn{VersesTranslationFields("text")}},
+			{name: "word fields", reqOpts: []VersesReqOptFn{VersesWordFields("transliteration")}},
+			{name: "exclude words", reqOpts: []VersesReqOptFn{VersesIncludeWords(false)}},
+			{name: "exclude audio", reqOpts: []VersesReqOptFn{VersesIncludeAudio(false)}},
+			{name: "include related", reqOpts: []VersesReqOptFn{VersesIncludeRelated(true)}},
+			{name: "page and limit", reqOpts: []VersesReqOptFn{VersesPage(2), VersesLimit(20)}},
+			{name: "limit and offset", reqOpts: []VersesReqOptFn{VersesLimit(20), VersesOffset(20)}},
+		}
+
+		seen := make(map[string]string, len(tests))
+		for _, tt := range tests {
+			key := string(versesKey(t, 1, tt.reqOpts...))
+			if other, ok := seen[key]; ok {
+				t.Errorf("%s has the same key as %s", tt.name, other)
+				continue
+			}
+			seen[key] = tt.name
+		}
+	})
+
+	t.Run("chapters have distinct keys", func(t *testing.T) {
+		if bytes.Equal(versesKey(t, 1), versesKey(t, 2)) {
+			t.Error("chapters 1 and 2 have the same key")
+		}
+	})
+
+	t.Run("options that do not change the response share a key", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			reqOpts []VersesReqOptFn
+			want    []VersesReqOptFn
+		}{
+			{
+				name:    "no cache",
+				reqOpts: []VersesReqOptFn{VersesNoCache()},
+			},
+			{
+				name:    "keep arrival order",
+				reqOpts: []VersesReqOptFn{VersesKeepArrivalOrder()},
+			},
+			{
+				name:    "no cache with other options",
+				reqOpts: []VersesReqOptFn{VersesTranslations([]int{20}), VersesNoCache()},
+				want:    []VersesReqOptFn{VersesTranslations([]int{20})},
+			},
+			{
+				name:    "unordered translations",
+				reqOpts: []VersesReqOptFn{VersesTranslations([]int{85, 20})},
+				want:    []VersesReqOptFn{VersesTranslations([]int{20, 85})},
+			},
+			{
+				name:    "unordered media",
+				reqOpts: []VersesReqOptFn{VersesMedia([]int{3, 1})},
+				want:    []VersesReqOptFn{VersesMedia([]int{1, 3})},
+			},
+			{
+				name:    "unordered translation fields",
+				reqOpts: []VersesReqOptFn{VersesTranslationFields("text", "resource_name")},
+				want:    []VersesReqOptFn{VersesTranslationFields("resource_name", "text")},
+			},
+			{
+				name:    "unordered word fields",
+				reqOpts: []VersesReqOptFn{VersesWordFields("transliteration", "translation")},
+				want:    []VersesReqOptFn{VersesWordFields("translation", "transliteration")},
+			},
+			{
+				name:    "recitation without audio",
+				reqOpts: []VersesReqOptFn{VersesRecitation(7), VersesIncludeAudio(false)},
+				want:    []VersesReqOptFn{VersesIncludeAudio(false)},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, want := versesKey(t, 1, tt.reqOpts...), versesKey(t, 1, tt.want...)
+				if !bytes.Equal(got, want) {
+					t.Error("keys differ")
+				}
+			})
+		}
+	})
+}
+
+func TestWithHost_trailingSlash(t *testing.T) {
+	tests := []struct {
+		name   string
+		suffix string
+	}{
+		{name: "no trailing slash"},
+		{name: "trailing slash", suffix: "/"},
+		{name: "trailing slashes", suffix: "//"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				paths []string
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				paths = append(paths, r.URL.Path)
+				mu.Unlock()
+				writeJSON(w, map[string]interface{}{"chapters": []Chapter{}})
+			}))
+			t.Cleanup(srv.Close)
+
+			c := New(WithHost(srv.URL + tt.suffix))
+			if _, err := c.Chapters(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if want := []string{"/api/v3/chapters"}; !reflect.DeepEqual(paths, want) {
+				t.Errorf("got paths %q, want %q", paths, want)
+			}
+		})
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		elems []string
+		want  string
+	}{
+		{elems: []string{"api", "v3"}, want: "/api/v3"},
+		{elems: []string{"/api/", "/v3/"}, want: "/api/v3"},
+		{elems: []string{"chapters", "", "verses"}, want: "/chapters/verses"},
+		{elems: []string{"//verses//", "by_page", "1"}, want: "/verses/by_page/1"},
+		{elems: nil, want: "/"},
+	}
+
+	for _, tt := range tests {
+		if got := joinPath(tt.elems...); got != tt.want {
+			t.Errorf("joinPath(%q) = %q, want %q", tt.elems, got, tt.want)
+		}
+	}
+}
+
+func TestPages_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    Pages
+		wantErr bool
+	}{
+		{name: "array", json: `[1, 2]`, want: Pages{Start: 1, End: 2}},
+		{name: "object", json: `{"start": 1, "end": 2}`, want: Pages{Start: 1, End: 2}},
+		{name: "padded object", json: ` {"end": 49, "start": 2} `, want: Pages{Start: 2, End: 49}},
+		{name: "null", json: `null`},
+		{name: "short array", json: `[1]`, wantErr: true},
+		{name: "long array", json: `[1, 2, 3]`, wantErr: true},
+		{name: "array of strings", json: `["1", "2"]`, wantErr: true},
+		{name: "object of strings", json: `{"start": "1", "end": "2"}`, wantErr: true},
+		{name: "string", json: `"1-2"`, wantErr: true},
+		{name: "number", json: `1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Pages
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("in a chapter", func(t *testing.T) {
+		var ch Chapter
+		if err := json.Unmarshal([]byte(`{"id": 2, "pages": [2, 49]}`), &ch); err != nil {
+			t.Fatal(err)
+		}
+		if ch.Pages != (Pages{Start: 2, End: 49}) {
+			t.Errorf("got pages %+v", ch.Pages)
+		}
+	})
+}
+
+func TestWithSortedTranslations(t *testing.T) {
+	ctx := context.Background()
+
+	// the stub serves every verse with its translations out of resource id order.
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verse := Verse{ChapterID: 1, VerseNumber: 1, VerseKey: "1:1", Translations: []Resource{{ResourceID: 131}, {ResourceID: 20}, {ResourceID: 33}}}
+		if strings.HasSuffix(r.URL.Path, "/verses/1") {
+			writeJSON(w, map[string]interface{}{"verse": verse})
+			return
+		}
+		writeJSON(w, map[string]interface{}{"verses": []Verse{verse}})
+	})
+
+	calls := map[string]func(c *Client) ([]Verse, error){
+		"Verses": func(c *Client) ([]Verse, error) {
+			return c.Verses(ctx, 1)
+		},
+		"Verse": func(c *Client) ([]Verse, error) {
+			v, err := c.Verse(ctx, 1, 1)
+			return []Verse{v}, err
+		},
+		"VersesByPage": func(c *Client) ([]Verse, error) {
+			return c.VersesByPage(ctx, 1)
+		},
+	}
+
+	tests := []struct {
+		name string
+		opts []ClientOptFn
+		want []int
+	}{
+		{name: "api order by default", want: []int{131, 20, 33}},
+		{name: "sorted", opts: []ClientOptFn{WithSortedTranslations(true)}, want: []int{20, 33, 131}},
+		{name: "api order when off", opts: []ClientOptFn{WithSortedTranslations(false)}, want: []int{131, 20, 33}},
+	}
+
+	for _, tt := range tests {
+		for name, call := range calls {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				verses, err := call(newHandlerClient(t, h, tt.opts...))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(verses) != 1 {
+					t.Fatalf("got %d verses, want 1", len(verses))
+				}
+				got := []int{}
+				for _, r := range verses[0].Translations {
+					got = append(got, r.ResourceID)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("got translations %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestResource_NormalizedResourceName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "already normalized", in: "saheeh international", want: "saheeh international"},
+		{name: "title case", in: "Saheeh International", want: "saheeh international"},
+		{name: "upper case", in: "SAHEEH INTERNATIONAL", want: "saheeh international"},
+		{name: "surrounding whitespace", in: "  Saheeh International\n", want: "saheeh international"},
+		{name: "repeated whitespace", in: "Saheeh \t International", want: "saheeh international"},
+		{name: "non-breaking space", in: "Saheeh\u00a0International", want: "saheeh international"},
+		{name: "non-ascii letters", in: "Muhammad Taqi-ud-Din al-HILĀLĪ", want: "muhammad taqi-ud-din al-hilālī"},
+		{name: "punctuation is kept", in: "Dr.  Mustafa Khattab, The Clear Quran", want: "dr. mustafa khattab, the clear quran"},
+		{name: "punctuation variants differ", in: "Dr Mustafa Khattab The Clear Quran", want: "dr mustafa khattab the clear quran"},
+		{name: "whitespace only", in: " \t ", want: ""},
+		{name: "empty", in: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Resource{ResourceName: tt.in}
+			if got := r.NormalizedResourceName(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if r.ResourceName != tt.in {
+				t.Errorf("got the raw name changed to %q", r.ResourceName)
+			}
+		})
+	}
+}
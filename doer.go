@@ -0,0 +1,281 @@
+package quranc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// doerFunc is an adapter to allow the use of ordinary functions as a Doer.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// RateLimitInfo is the rate limit state quran.com reports on a response. Fields whose
+// header is missing from the response are left zero.
+type RateLimitInfo struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// rateLimitObserverDoer calls observe with the rate limit state of every response that
+// reports it, reset seconds are resolved against the clock.
+func rateLimitObserverDoer(next Doer, observe func(RateLimitInfo), now func() time.Time) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := next.Do(r)
+		if err != nil {
+			return resp, err
+		}
+
+		if info, ok := parseRateLimit(resp.Header, now()); ok {
+			observe(info)
+		}
+		return resp, nil
+	})
+}
+
+// parseRateLimit parses the X-RateLimit headers. The reset header is sent as either the
+// number of seconds until the limit resets or as a unix timestamp, values too large to be
+// a sane number of seconds are treated as the latter.
+func parseRateLimit(h http.Header, now time.Time) (RateLimitInfo, bool) {
+	var (
+		info  RateLimitInfo
+		found bool
+	)
+	if i, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+		info.Limit, found = i, true
+	}
+	if i, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil {
+		info.Remaining, found = i, true
+	}
+	if i, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		const oneYear = 365 * 24 * 60 * 60
+		if i > oneYear {
+			info.Reset = time.Unix(i, 0)
+		} else {
+			info.Reset = now.Add(time.Duration(i) * time.Second)
+		}
+		found = true
+	}
+	return info, found
+}
+
+// rateLimitDoer waits on the limiter before every request is sent. When the request's
+// context is done first, the context's error is returned in place of the limiter's so it
+// surfaces from the call unwrapped.
+func rateLimitDoer(next Doer, limiter *rate.Limiter) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		ctx := r.Context()
+		if err := limiter.Wait(ctx); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			}
+			setResponseErr(ctx, err)
+			return nil, err
+		}
+		return next.Do(r)
+	})
+}
+
+// LogRecord describes a request sent to quran.com and its outcome. It carries no
+// headers, so tokens set with WithBearerToken or WithHeader are never logged.
+type LogRecord struct {
+	Method     string
+	Path       string
+	Query      url.Values
+	StatusCode int
+	Duration   time.Duration
+	// Err is the error sending the request, an unsuccessful status is not an error.
+	Err error
+}
+
+// loggingDoer calls log with a record of every request once it completes, timed by the
+// clock.
+func loggingDoer(next Doer, log func(LogRecord), now func() time.Time) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		start := now()
+		resp, err := next.Do(r)
+
+		rec := LogRecord{
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			Query:    r.URL.Query(),
+			Duration: now().Sub(start),
+			Err:      err,
+		}
+		if resp != nil {
+			rec.StatusCode = resp.StatusCode
+		}
+		log(rec)
+		return resp, err
+	})
+}
+
+// headerDoer adds the headers to every request, headers already set on the request are
+// left as they are.
+func headerDoer(next Doer, headers http.Header) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		for k, vals := range headers {
+			if _, ok := r.Header[k]; ok {
+				continue
+			}
+			for _, v := range vals {
+				r.Header.Add(k, v)
+			}
+		}
+		return next.Do(r)
+	})
+}
+
+// requestMiddlewareDoer calls fn with every request before it is sent, an error from fn
+// aborts the request.
+func requestMiddlewareDoer(next Doer, fn func(*http.Request) error) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		if err := fn(r); err != nil {
+			return nil, err
+		}
+		return next.Do(r)
+	})
+}
+
+// responseInspectorDoer calls inspect with every response received. The body is read
+// up front and teed, so the inspector may read a copy of it without consuming the body
+// the response is decoded from.
+func responseInspectorDoer(next Doer, inspect func(*http.Response)) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := next.Do(r)
+		if err != nil {
+			return resp, err
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		inspected := *resp
+		inspected.Body = ioutil.NopCloser(bytes.NewReader(body))
+		inspect(&inspected)
+
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	})
+}
+
+// responseErrKey is the context key of the responseErr slot.
+type responseErrKey struct{}
+
+// responseErr is a slot on the request's context a doer sets a typed error on. httpc
+// replaces an unsuccessful response with an error of its own, the slot carries the typed
+// error past it to Client.do.
+type responseErr struct {
+	err error
+}
+
+func withResponseErr(ctx context.Context) (context.Context, *responseErr) {
+	slot := new(responseErr)
+	return context.WithValue(ctx, responseErrKey{}, slot), slot
+}
+
+func setResponseErr(ctx context.Context, err error) {
+	if slot, ok := ctx.Value(responseErrKey{}).(*responseErr); ok {
+		slot.err = err
+	}
+}
+
+func responseErrFrom(ctx context.Context) error {
+	if slot, ok := ctx.Value(responseErrKey{}).(*responseErr); ok {
+		return slot.err
+	}
+	return nil
+}
+
+// maxMaintenanceBody bounds how much of a 503's body is read looking for maintenance.
+const maxMaintenanceBody = 64 << 10
+
+// maintenanceDoer reports ErrMaintenance for the 503s quran.com sends while it is down for
+// maintenance. The response is passed on untouched, with its body restored.
+func maintenanceDoer(next Doer) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := next.Do(r)
+		if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, err
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxMaintenanceBody))
+		if err != nil {
+			return resp, nil
+		}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+		if isMaintenance(body) {
+			setResponseErr(r.Context(), ErrMaintenance)
+		}
+		return resp, nil
+	})
+}
+
+// maxErrorBody bounds how much of an unsuccessful response's body is kept on its
+// APIError.
+const maxErrorBody = 64 << 10
+
+// apiErrorDoer sets an *APIError on the response error slot for every unsuccessful
+// response, unless a doer closer to the request has set a more specific error on it. The
+// response is passed on with its body restored.
+func apiErrorDoer(next Doer) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := next.Do(r)
+		if err != nil || resp.StatusCode < 300 || responseErrFrom(r.Context()) != nil {
+			return resp, err
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		if err != nil {
+			setResponseErr(r.Context(), newAPIError(resp.StatusCode, nil))
+			return resp, nil
+		}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+		setResponseErr(r.Context(), newAPIError(resp.StatusCode, body))
+		return resp, nil
+	})
+}
+
+// isMaintenance reports whether the body of a 503 is quran.com's maintenance response, a
+// json object with a true maintenance flag, or a message or error mentioning maintenance.
+// Bodies that are not json objects are never maintenance.
+func isMaintenance(body []byte) bool {
+	var resp map[string]interface{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false
+	}
+
+	if maintenance, ok := resp["maintenance"].(bool); ok {
+		return maintenance
+	}
+	for _, field := range []string{"message", "error", "status"} {
+		if msg, ok := resp[field].(string); ok && strings.Contains(strings.ToLower(msg), "maintenance") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,456 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_rateLimitObserver(t *testing.T) {
+	clock := newFakeClock()
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    RateLimitInfo
+		// observed reports whether the observer is called at all.
+		observed bool
+	}{
+		{
+			name: "reset in seconds is resolved against the clock",
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "60",
+				"X-RateLimit-Remaining": "59",
+				"X-RateLimit-Reset":     "30",
+			},
+			want:     RateLimitInfo{Limit: 60, Remaining: 59, Reset: clock.now().Add(30 * time.Second)},
+			observed: true,
+		},
+		{
+			name:     "reset as a unix timestamp",
+			headers:  map[string]string{"X-RateLimit-Reset": "1893456000"},
+			want:     RateLimitInfo{Reset: time.Unix(1893456000, 0)},
+			observed: true,
+		},
+		{
+			name:     "remaining only",
+			headers:  map[string]string{"X-RateLimit-Remaining": "0"},
+			want:     RateLimitInfo{},
+			observed: true,
+		},
+		{
+			name:    "no headers",
+			headers: map[string]string{"X-RateLimit-Limit": "unlimited"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.Write([]byte(`{"chapters": []}`))
+			})
+
+			var observed []RateLimitInfo
+			c := newHandlerClient(t, h,
+				WithClientClock(clock.now),
+				WithRateLimitObserver(func(info RateLimitInfo) { observed = append(observed, info) }),
+			)
+			if _, err := c.Chapters(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.observed {
+				if len(observed) != 0 {
+					t.Errorf("got %d observations, want none", len(observed))
+				}
+				return
+			}
+			if len(observed) != 1 {
+				t.Fatalf("got %d observations, want 1", len(observed))
+			}
+			if got := observed[0]; got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.Reset.Equal(tt.want.Reset) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_logger(t *testing.T) {
+	t.Run("every attempt is logged", func(t *testing.T) {
+		var requests int32
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch atomic.AddInt32(&requests, 1) {
+			case 1:
+				w.WriteHeader(http.StatusInternalServerError)
+			case 2:
+				w.Write([]byte(`{"verses": [], "meta": {}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		var records []LogRecord
+		c := newHandlerClient(t, h,
+			WithRetry(2, time.Millisecond),
+			WithBearerToken("secret"),
+			WithLogger(func(rec LogRecord) { records = append(records, rec) }),
+		)
+		ctx := context.Background()
+		if _, err := c.Verses(ctx, 2, VersesPage(3)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Search(ctx, SearchRequest{Query: "mercy"}); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("got error %v, want ErrNotFound", err)
+		}
+
+		tests := []struct {
+			name       string
+			path       string
+			query      string
+			wantStatus int
+		}{
+			{name: "failed attempt", path: "/api/v3/chapters/2/verses", query: "page=3", wantStatus: http.StatusInternalServerError},
+			{name: "retried attempt", path: "/api/v3/chapters/2/verses", query: "page=3", wantStatus: http.StatusOK},
+			// an unsuccessful status is not an error, it is not retried either.
+			{name: "not found", path: "/api/v3/search", query: "q=mercy", wantStatus: http.StatusNotFound},
+		}
+		if len(records) != len(tests) {
+			t.Fatalf("got %d records, want %d", len(records), len(tests))
+		}
+		for i, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rec := records[i]
+				if rec.Method != http.MethodGet || rec.Path != tt.path || rec.Query.Encode() != tt.query {
+					t.Errorf("got %s %s?%s, want GET %s?%s", rec.Method, rec.Path, rec.Query.Encode(), tt.path, tt.query)
+				}
+				if rec.StatusCode != tt.wantStatus {
+					t.Errorf("got status %d, want %d", rec.StatusCode, tt.wantStatus)
+				}
+				if rec.Err != nil {
+					t.Errorf("got error %v", rec.Err)
+				}
+				if strings.Contains(fmt.Sprintf("%+v", rec), "secret") {
+					t.Error("the bearer token is logged")
+				}
+			})
+		}
+	})
+
+	t.Run("a request that fails to send is logged with its error", func(t *testing.T) {
+		var records []LogRecord
+		c := New(
+			WithHost("http://127.0.0.1:1"),
+			WithLogger(func(rec LogRecord) { records = append(records, rec) }),
+		)
+		if _, err := c.Chapters(context.Background()); err == nil {
+			t.Fatal("got no error")
+		}
+		if len(records) != 1 || records[0].Err == nil || records[0].StatusCode != 0 {
+			t.Errorf("got records %+v, want a single record of the error", records)
+		}
+	})
+
+	t.Run("durations are timed by the clock", func(t *testing.T) {
+		clock := newFakeClock()
+		clock.step = time.Second
+
+		var records []LogRecord
+		c := newTestClient(t, `{"chapters": []}`,
+			WithClientClock(clock.now),
+			WithLogger(func(rec LogRecord) { records = append(records, rec) }),
+		)
+		if _, err := c.Chapters(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(records) != 1 {
+			t.Fatalf("got %d records, want 1", len(records))
+		}
+		if got := records[0].Duration; got != time.Second {
+			t.Errorf("got duration %s, want the clock's step of 1s", got)
+		}
+	})
+}
+
+func TestClient_maintenance(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		wantMaintenance bool
+	}{
+		{name: "maintenance flag", status: http.StatusServiceUnavailable, body: `{"maintenance": true}`, wantMaintenance: true},
+		{name: "maintenance message", status: http.StatusServiceUnavailable, body: `{"message": "Down for Maintenance"}`, wantMaintenance: true},
+		{name: "maintenance status", status: http.StatusServiceUnavailable, body: `{"status": "under maintenance"}`, wantMaintenance: true},
+		{name: "flag unset", status: http.StatusServiceUnavailable, body: `{"maintenance": false, "message": "maintenance"}`},
+		{name: "other 503", status: http.StatusServiceUnavailable, body: `{"message": "overloaded"}`},
+		{name: "html 503", status: http.StatusServiceUnavailable, body: `<html>maintenance</html>`},
+		{name: "maintenance body on a 500", status: http.StatusInternalServerError, body: `{"maintenance": true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			c := newHandlerClient(t, h)
+
+			_, err := c.Chapters(context.Background())
+			if got := errors.Is(err, ErrMaintenance); got != tt.wantMaintenance {
+				t.Fatalf("got error %v, want maintenance %t", err, tt.wantMaintenance)
+			}
+			if tt.wantMaintenance {
+				return
+			}
+
+			// other failures are passed on with their body intact.
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got error %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || string(apiErr.Body) != tt.body {
+				t.Errorf("got %d %q, want %d %q", apiErr.StatusCode, apiErr.Body, tt.status, tt.body)
+			}
+		})
+	}
+}
+
+func TestClient_apiError(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantMessage  string
+		wantText     string
+		wantNotFound bool
+	}{
+		{name: "error field", status: http.StatusBadRequest, body: `{"error": "invalid chapter"}`, wantMessage: "invalid chapter", wantText: "quran.com responded 400: invalid chapter"},
+		{name: "message field", status: http.StatusUnprocessableEntity, body: `{"message": "bad page"}`, wantMessage: "bad page", wantText: "quran.com responded 422: bad page"},
+		{name: "status field", status: http.StatusInternalServerError, body: `{"status": "failed"}`, wantMessage: "failed", wantText: "quran.com responded 500: failed"},
+		{name: "error ahead of message", status: http.StatusBadRequest, body: `{"message": "second", "error": "first"}`, wantMessage: "first", wantText: "quran.com responded 400: first"},
+		{name: "numeric status", status: http.StatusBadGateway, body: `{"status": 502}`, wantText: "quran.com responded 502: Bad Gateway"},
+		{name: "html body", status: http.StatusBadGateway, body: `<html>bad gateway</html>`, wantText: "quran.com responded 502: Bad Gateway"},
+		{name: "not found", status: http.StatusNotFound, body: `{"error": "not found"}`, wantMessage: "not found", wantText: "quran.com responded 404: not found", wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+			c := newHandlerClient(t, h)
+
+			_, err := c.Chapters(context.Background())
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got error %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMessage || string(apiErr.Body) != tt.body {
+				t.Errorf("got %+v", apiErr)
+			}
+			if got := err.Error(); got != tt.wantText {
+				t.Errorf("got error text %q, want %q", got, tt.wantText)
+			}
+			if got := errors.Is(err, ErrNotFound); got != tt.wantNotFound {
+				t.Errorf("got errors.Is(err, ErrNotFound) %t, want %t", got, tt.wantNotFound)
+			}
+		})
+	}
+}
+
+func TestClient_headers(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOptFn
+		want http.Header
+	}{
+		{
+			name: "default user agent",
+			want: http.Header{"User-Agent": {"quranc-go/" + Version}},
+		},
+		{
+			name: "header",
+			opts: []ClientOptFn{WithHeader("X-Api-Key", "key")},
+			want: http.Header{"X-Api-Key": {"key"}, "User-Agent": {"quranc-go/" + Version}},
+		},
+		{
+			name: "headers accumulate",
+			opts: []ClientOptFn{WithHeader("Accept-Language", "en"), WithHeader("Accept-Language", "ar")},
+			want: http.Header{"Accept-Language": {"en", "ar"}},
+		},
+		{
+			name: "bearer token",
+			opts: []ClientOptFn{WithBearerToken("token")},
+			want: http.Header{"Authorization": {"Bearer token"}},
+		},
+		{
+			name: "user agent header",
+			opts: []ClientOptFn{WithHeader("User-Agent", "app/1.0")},
+			want: http.Header{"User-Agent": {"app/1.0"}},
+		},
+		{
+			name: "user agent over header",
+			opts: []ClientOptFn{WithUserAgent("app/2.0"), WithHeader("User-Agent", "app/1.0")},
+			want: http.Header{"User-Agent": {"app/2.0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.Write([]byte(`{"chapters": []}`))
+			})
+			c := newHandlerClient(t, h, tt.opts...)
+			if _, err := c.Chapters(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			for k, want := range tt.want {
+				if vals := got.Values(k); !reflect.DeepEqual(vals, want) {
+					t.Errorf("got %s %q, want %q", k, vals, want)
+				}
+			}
+		})
+	}
+
+	t.Run("options do not share headers", func(t *testing.T) {
+		base := WithHeader("X-Api-Key", "first")
+		var opt clientOpt
+		opt = base(opt)
+		second := WithHeader("X-Api-Key", "second")(opt)
+		if vals := opt.headers.Values("X-Api-Key"); !reflect.DeepEqual(vals, []string{"first"}) {
+			t.Errorf("got %q, adding a header changed the options it was added to", vals)
+		}
+		if vals := second.headers.Values("X-Api-Key"); !reflect.DeepEqual(vals, []string{"first", "second"}) {
+			t.Errorf("got %q, want both values", vals)
+		}
+	})
+}
+
+func TestClient_requestMiddleware(t *testing.T) {
+	errAbort := errors.New("abort")
+
+	tests := []struct {
+		name         string
+		middleware   func(r *http.Request) error
+		wantQuery    string
+		wantHeader   string
+		wantRequests int32
+		wantErr      error
+	}{
+		{
+			name: "query param",
+			middleware: func(r *http.Request) error {
+				q := r.URL.Query()
+				q.Set("api_key", "key")
+				r.URL.RawQuery = q.Encode()
+				return nil
+			},
+			wantQuery:    "api_key=key",
+			wantRequests: 1,
+		},
+		{
+			name: "header",
+			middleware: func(r *http.Request) error {
+				r.Header.Set("X-Request-Id", "1")
+				return nil
+			},
+			wantHeader:   "1",
+			wantRequests: 1,
+		},
+		{
+			name:       "an error aborts the call",
+			middleware: func(r *http.Request) error { return errAbort },
+			wantErr:    errAbort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				if got := r.URL.RawQuery; got != tt.wantQuery {
+					t.Errorf("got query %q, want %q", got, tt.wantQuery)
+				}
+				if got := r.Header.Get("X-Request-Id"); got != tt.wantHeader {
+					t.Errorf("got request id %q, want %q", got, tt.wantHeader)
+				}
+				w.Write([]byte(`{"chapters": []}`))
+			})
+			c := newHandlerClient(t, h, WithRequestMiddleware(tt.middleware))
+
+			_, err := c.Chapters(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if n := atomic.LoadInt32(&requests); n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClient_responseInspector(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantErr      bool
+		wantChapters int
+	}{
+		{name: "success", status: http.StatusOK, body: `{"chapters": [{"id": 1}, {"id": 2}]}`, wantChapters: 2},
+		{name: "failure", status: http.StatusBadGateway, body: `{"error": "upstream"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			var inspected []string
+			c := newHandlerClient(t, h, WithResponseInspector(func(resp *http.Response) {
+				// the inspector reads the whole body, the client still decodes its own copy.
+				body, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					t.Error(err)
+				}
+				inspected = append(inspected, fmt.Sprintf("%d %s %s", resp.StatusCode, resp.Header.Get("Cache-Control"), body))
+			}))
+
+			chapters, err := c.Chapters(context.Background())
+			if got := err != nil; got != tt.wantErr {
+				t.Fatalf("got error %v, want an error %t", err, tt.wantErr)
+			}
+			if len(chapters) != tt.wantChapters {
+				t.Errorf("got %d chapters, want %d", len(chapters), tt.wantChapters)
+			}
+			var apiErr *APIError
+			if tt.wantErr && (!errors.As(err, &apiErr) || string(apiErr.Body) != tt.body) {
+				t.Errorf("got error %v, want an *APIError with the body", err)
+			}
+
+			want := fmt.Sprintf("%d max-age=60 %s", tt.status, tt.body)
+			if len(inspected) != 1 || inspected[0] != want {
+				t.Errorf("inspected %q, want %q", inspected, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package quranc
+
+import (
+	"context"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+// maxVersesLimit is the largest page of verses the api will return.
+const maxVersesLimit = 50
+
+// defaultVersesLimit is the page of verses the api returns when no limit is set.
+const defaultVersesLimit = 10
+
+// DownloadAll warms the bolt cache with the verses of every chapter, using the verse options
+// provided, i.e. the text and a translation, for offline use. Chapters are downloaded with
+// the client's default concurrency, set with WithDefaultConcurrency. Pages of verses that
+// are already cached are read from the cache, so an interrupted download resumes where it
+// left off when run again. The chapters that fail do not stop the others, their errors are
+// returned together as a MultiError. Progress is reported per chapter to the progress
+// function, which may be nil. The progress made is returned alongside any error.
+//
+// The context's deadline is the budget of the download as a whole, separate from the
+// timeout of each request. Once it passes the chapters in flight stop at their next
+// request, what was cached so far stays cached, and the context's error is returned alone
+// so a timeout can be told apart from failed chapters.
+func (c *Client) DownloadAll(ctx context.Context, db *bbolt.DB, progress ProgressFunc, reqOpts ...VersesReqOptFn) (Progress, error) {
+	cache, err := BoltCache(c, db)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	chapters, err := cache.Chapters(ctx)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	chapterOf := func(i int) int { return chapters[i].ChapterNumber }
+	return batch(ctx, len(chapters), apiConcurrency(cache, 0), progress, chapterOf, func(ctx context.Context, i int) error {
+		ch := chapters[i]
+		if _, err := chapterVerses(ctx, cache, ch.ChapterNumber, ch.VersesCount, reqOpts...); err != nil {
+			return fmt.Errorf("chapter %d: %w", ch.ChapterNumber, err)
+		}
+		return nil
+	})
+}
+
+// chapterVerses fetches all the verses of a chapter one page at a time, as the api
+// only returns a page of verses per call. The api does not expose the pagination meta,
+// so a short page is taken as the last one, which stops a route that ignores paging
+// from being fetched over and over.
+func chapterVerses(ctx context.Context, api QuranAPI, chapterID, versesCount int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	verses := make([]Verse, 0, versesCount)
+	for page := 1; len(verses) < versesCount; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		opts := append(reqOpts[:len(reqOpts):len(reqOpts)], VersesLimit(maxVersesLimit), VersesPage(page))
+		pageVerses, err := api.Verses(ctx, chapterID, opts...)
+		if err != nil {
+			return nil, err
+		}
+		verses = append(verses, pageVerses...)
+		if len(pageVerses) < maxVersesLimit {
+			break
+		}
+	}
+	return verses, nil
+}
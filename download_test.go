@@ -0,0 +1,97 @@
+package quranc
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClient_DownloadAll(t *testing.T) {
+	t.Run("resume skips cached chapters", func(t *testing.T) {
+		ctx := context.Background()
+		stub := &versesStub{chapters: StaticChapters()[:3]}
+		stub.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path != "/api/v3/chapters/2/verses" {
+				return false
+			}
+			http.Error(w, `{"error": "unavailable"}`, http.StatusInternalServerError)
+			return true
+		}
+		c := newHandlerClient(t, stub)
+		db := newTestDB(t)
+
+		progress, err := c.DownloadAll(ctx, db, nil)
+		if err == nil {
+			t.Fatal("got no error downloading a failing chapter")
+		}
+		if want := (Progress{Done: 2, Total: 3}); progress.Done != want.Done || progress.Total != want.Total {
+			t.Fatalf("got progress %+v, want %d of %d done", progress, want.Done, want.Total)
+		}
+
+		stub.intercept = nil
+		before := map[string]int{
+			"/api/v3/chapters":          stub.requestCount("/api/v3/chapters"),
+			"/api/v3/chapters/1/verses": stub.requestCount("/api/v3/chapters/1/verses"),
+			"/api/v3/chapters/3/verses": stub.requestCount("/api/v3/chapters/3/verses"),
+		}
+
+		progress, err = c.DownloadAll(ctx, db, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if progress.Done != 3 || progress.Total != 3 {
+			t.Fatalf("got progress %+v, want 3 of 3 done", progress)
+		}
+		for path, want := range before {
+			if got := stub.requestCount(path); got != want {
+				t.Errorf("resume sent %d requests to %s, want none", got-want, path)
+			}
+		}
+		// chapter 2 is 286 verses, 6 pages of 50 plus the failed first attempt.
+		if got := stub.requestCount("/api/v3/chapters/2/verses"); got != 7 {
+			t.Errorf("got %d requests for chapter 2, want 7", got)
+		}
+
+		total := stub.totalRequests()
+		if _, err := c.DownloadAll(ctx, db, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := stub.totalRequests(); got != total {
+			t.Errorf("a complete download sent %d requests, want none", got-total)
+		}
+	})
+
+	t.Run("deadline stops the download", func(t *testing.T) {
+		stub := &versesStub{chapters: StaticChapters()[:3]}
+		stub.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path == "/api/v3/chapters" || r.URL.Path == "/api/v3/chapters/1/verses" {
+				return false
+			}
+			// stall until the client gives up on the request.
+			<-r.Context().Done()
+			return true
+		}
+		c := newHandlerClient(t, stub, WithDefaultConcurrency(2))
+		db := newTestDB(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		progress, err := c.DownloadAll(ctx, db, nil)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("download took %s to stop after the deadline", elapsed)
+		}
+		if err != context.DeadlineExceeded {
+			t.Fatalf("got error %v, want context.DeadlineExceeded", err)
+		}
+		cached := bucketLen(t, db, bucketVerses)
+		if cached != 1 {
+			t.Fatalf("got %d cached pages of verses, want the 1 page of chapter 1", cached)
+		}
+		if progress.Done < cached || progress.Total != 3 {
+			t.Errorf("got progress %+v, want at least %d of 3 done", progress, cached)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package quranc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrInvalidMedia is returned when a media id provided to VersesMedia is invalid.
+	ErrInvalidMedia = errors.New("invalid media id")
+
+	// ErrMaintenance is returned when quran.com is down for maintenance. It is distinct
+	// from other 503s as maintenance usually lasts long enough to warrant backing off for
+	// longer than a retry.
+	ErrMaintenance = errors.New("quran.com is down for maintenance")
+
+	// ErrUnsupportedAPIVersion is returned, before any request is sent, by the methods and
+	// options that have not been ported to the api version set with WithAPIVersion.
+	ErrUnsupportedAPIVersion = errors.New("not supported by quran.com api version")
+)
+
+// APIError is returned when quran.com responds with an unsuccessful status. A 404 is
+// ErrNotFound, errors.Is(err, ErrNotFound) holds for it.
+type APIError struct {
+	StatusCode int
+	// Message is the error quran.com sent in the body, empty when the body is not one of
+	// its error responses.
+	Message string
+	Body    []byte
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("quran.com responded %d: %s", e.StatusCode, msg)
+}
+
+// Is reports whether the target is ErrNotFound and the error is a 404.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// newAPIError builds the APIError of an unsuccessful response. quran.com's error bodies
+// are a json object with either an error or a message, or a status that is a string.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Body: body}
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return apiErr
+	}
+	for _, field := range []string{"error", "message", "status"} {
+		if msg, ok := resp[field].(string); ok && msg != "" {
+			apiErr.Message = msg
+			break
+		}
+	}
+	return apiErr
+}
+
+// MultiError is the errors of a batch operation that carries on past individual failures.
+type MultiError []error
+
+func (m MultiError) Error() string {
+	if len(m) == 1 {
+		return m[0].Error()
+	}
+
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(m), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the errors is the target, so errors.Is(err, context.Canceled)
+// holds for a batch that was cancelled.
+func (m MultiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches the target, i.e. an *APIError.
+func (m MultiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
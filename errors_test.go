@@ -0,0 +1,102 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMultiError(t *testing.T) {
+	apiErr := &APIError{StatusCode: 500}
+	errs := MultiError{
+		fmt.Errorf("verse 1:1: %w", apiErr),
+		fmt.Errorf("verse 1:2: %w", ErrMaintenance),
+		context.DeadlineExceeded,
+	}
+
+	for _, target := range []error{ErrMaintenance, context.DeadlineExceeded} {
+		if !errors.Is(errs, target) {
+			t.Errorf("errors.Is(%v) = false, want true", target)
+		}
+	}
+	if errors.Is(errs, context.Canceled) {
+		t.Error("errors.Is(context.Canceled) = true, want false")
+	}
+
+	var got *APIError
+	if !errors.As(errs, &got) || got != apiErr {
+		t.Errorf("errors.As got %v, want %v", got, apiErr)
+	}
+}
+
+func TestErrInvalidMedia(t *testing.T) {
+	ctx := context.Background()
+
+	// the stub answers every route with both a verse and a page of verses, so that each
+	// method finds the key it decodes.
+	var requests int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		verse := Verse{ChapterID: 1, VerseNumber: 1, VerseKey: "1:1"}
+		writeJSON(w, map[string]interface{}{"verse": verse, "verses": []Verse{verse}})
+	})
+	c := newHandlerClient(t, h)
+
+	calls := map[string]func(opts ...VersesReqOptFn) error{
+		"Verses": func(opts ...VersesReqOptFn) error {
+			_, err := c.Verses(ctx, 1, opts...)
+			return err
+		},
+		"Verse": func(opts ...VersesReqOptFn) error {
+			_, err := c.Verse(ctx, 1, 1, opts...)
+			return err
+		},
+		"VersesByPage": func(opts ...VersesReqOptFn) error {
+			_, err := c.VersesByPage(ctx, 1, opts...)
+			return err
+		},
+		"VersesByJuz": func(opts ...VersesReqOptFn) error {
+			_, err := c.VersesByJuz(ctx, 1, opts...)
+			return err
+		},
+		"RandomVerse": func(opts ...VersesReqOptFn) error {
+			_, err := c.RandomVerse(ctx, opts...)
+			return err
+		},
+	}
+
+	tests := []struct {
+		name    string
+		media   []int
+		wantErr bool
+	}{
+		{name: "valid media", media: []int{1, 2}},
+		{name: "zero media id", media: []int{1, 0}, wantErr: true},
+		{name: "negative media id", media: []int{-1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		for name, call := range calls {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				atomic.StoreInt32(&requests, 0)
+
+				err := call(VersesMedia(tt.media))
+				if !tt.wantErr {
+					if err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				if !errors.Is(err, ErrInvalidMedia) {
+					t.Errorf("got error %v, want ErrInvalidMedia", err)
+				}
+				if n := atomic.LoadInt32(&requests); n != 0 {
+					t.Errorf("sent %d requests with invalid media", n)
+				}
+			})
+		}
+	}
+}
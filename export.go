@@ -0,0 +1,181 @@
+package quranc
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Formats supported by ExportChapters.
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// chapterRecord is the chapter metadata written by ExportChapters.
+type chapterRecord struct {
+	ChapterNumber   int    `json:"chapter_number"`
+	NameSimple      string `json:"name_simple"`
+	NameComplex     string `json:"name_complex"`
+	NameArabic      string `json:"name_arabic"`
+	VersesCount     int    `json:"verses_count"`
+	RevelationPlace string `json:"revelation_place"`
+	RevelationOrder int    `json:"revelation_order"`
+	PageStart       int    `json:"page_start"`
+	PageEnd         int    `json:"page_end"`
+}
+
+var chapterCSVHeader = []string{
+	"chapter_number",
+	"name_simple",
+	"name_complex",
+	"name_arabic",
+	"verses_count",
+	"revelation_place",
+	"revelation_order",
+	"page_start",
+	"page_end",
+}
+
+// ExportChapters writes the metadata of every chapter to w in the given format, either
+// "csv" or "json". The metadata written is the chapter number, names, verse count,
+// revelation place and order, and the span of pages the chapter is printed on. Progress is
+// reported per chapter written to the progress function, which may be nil.
+func ExportChapters(ctx context.Context, api QuranAPI, w io.Writer, format string, progress ProgressFunc) error {
+	if format != ExportFormatCSV && format != ExportFormatJSON {
+		return fmt.Errorf("invalid export format %q: must be one of %q or %q", format, ExportFormatCSV, ExportFormatJSON)
+	}
+
+	chapters, err := api.Chapters(ctx)
+	if err != nil {
+		return err
+	}
+
+	records := make([]chapterRecord, 0, len(chapters))
+	for _, ch := range chapters {
+		records = append(records, chapterRecord{
+			ChapterNumber:   ch.ChapterNumber,
+			NameSimple:      ch.NameSimple,
+			NameComplex:     ch.NameComplex,
+			NameArabic:      ch.NameArabic,
+			VersesCount:     ch.VersesCount,
+			RevelationPlace: ch.RevelationPlace,
+			RevelationOrder: ch.RevelationOrder,
+			PageStart:       ch.Pages.Start,
+			PageEnd:         ch.Pages.End,
+		})
+	}
+
+	if format == ExportFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(records); err != nil {
+			return err
+		}
+		progress.report(Progress{Done: len(records), Total: len(records)})
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(chapterCSVHeader); err != nil {
+		return err
+	}
+	for i, r := range records {
+		err := cw.Write([]string{
+			strconv.Itoa(r.ChapterNumber),
+			r.NameSimple,
+			r.NameComplex,
+			r.NameArabic,
+			strconv.Itoa(r.VersesCount),
+			r.RevelationPlace,
+			strconv.Itoa(r.RevelationOrder),
+			strconv.Itoa(r.PageStart),
+			strconv.Itoa(r.PageEnd),
+		})
+		if err != nil {
+			return err
+		}
+		progress.report(Progress{
+			Done:           i + 1,
+			Total:          len(records),
+			CurrentChapter: r.ChapterNumber,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// StreamNDJSON writes every verse of the Qur'an to w as newline delimited json, one verse
+// per line, in chapter and verse order. Chapters are fetched through the api with its
+// default concurrency, but only as far ahead of the writer as the concurrency allows, so
+// memory stays bounded however slow w is. When w has a Flush method it is flushed after
+// every chapter. The stream stops at the first error, or once the context is done. Progress
+// is reported per chapter written to the progress function, which may be nil.
+func StreamNDJSON(ctx context.Context, api QuranAPI, w io.Writer, progress ProgressFunc, reqOpts ...VersesReqOptFn) error {
+	chapters, err := api.Chapters(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		verses []Verse
+		err    error
+	}
+	results := make([]chan result, len(chapters))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	sem := make(chan struct{}, apiConcurrency(api, 0))
+	go func() {
+		for i, ch := range chapters {
+			select {
+			case <-ctx.Done():
+				return
+			case sem <- struct{}{}:
+			}
+			go func(i int, ch Chapter) {
+				verses, err := VersesAll(ctx, api, ch.ChapterNumber, reqOpts...)
+				results[i] <- result{verses: verses, err: err}
+			}(i, ch)
+		}
+	}()
+
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(interface{ Flush() error })
+	for i, ch := range chapters {
+		var res result
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case res = <-results[i]:
+		}
+		<-sem
+		if res.err != nil {
+			return fmt.Errorf("chapter %d: %w", ch.ChapterNumber, res.err)
+		}
+
+		for _, v := range res.verses {
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+		}
+		if flusher != nil {
+			if err := flusher.Flush(); err != nil {
+				return err
+			}
+		}
+		progress.report(Progress{
+			Done:           i + 1,
+			Total:          len(chapters),
+			CurrentChapter: ch.ChapterNumber,
+		})
+	}
+	return nil
+}
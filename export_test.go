@@ -0,0 +1,184 @@
+package quranc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExportChapters_progress(t *testing.T) {
+	for _, format := range []string{ExportFormatCSV, ExportFormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			var updates []Progress
+			err := ExportChapters(context.Background(), staticChaptersAPI{}, ioutil.Discard, format, func(p Progress) {
+				updates = append(updates, p)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(updates) == 0 {
+				t.Fatal("got no progress updates")
+			}
+			for i, p := range updates {
+				if p.Total != 114 {
+					t.Errorf("update %d has a total of %d, want 114", i, p.Total)
+				}
+				if i > 0 && p.Done <= updates[i-1].Done {
+					t.Errorf("update %d went from %d to %d done", i, updates[i-1].Done, p.Done)
+				}
+			}
+			if last := updates[len(updates)-1]; last.Done != 114 {
+				t.Errorf("got a last update of %d done, want 114", last.Done)
+			}
+		})
+	}
+}
+
+// flushBuffer is a buffer counting the times it is flushed.
+type flushBuffer struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (b *flushBuffer) Flush() error {
+	b.flushes++
+	return nil
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	ctx := context.Background()
+	chapters := []Chapter{
+		{ID: 3, ChapterNumber: 3, VersesCount: 3},
+		{ID: 1, ChapterNumber: 1, VersesCount: 7},
+		{ID: 2, ChapterNumber: 2, VersesCount: 10},
+	}
+
+	// lastFirst holds back the verses of chapter 1 until chapter 3 is asked for, so the
+	// chapters after it are fetched first.
+	lastFirst := func() func(w http.ResponseWriter, r *http.Request) bool {
+		chapter3 := make(chan struct{})
+		var once sync.Once
+		return func(w http.ResponseWriter, r *http.Request) bool {
+			switch r.URL.Path {
+			case "/api/v3/chapters/3/verses":
+				once.Do(func() { close(chapter3) })
+			case "/api/v3/chapters/1/verses":
+				select {
+				case <-chapter3:
+				case <-time.After(5 * time.Second):
+					t.Error("chapter 3 was not fetched while chapter 1 was")
+				}
+			}
+			return false
+		}
+	}
+	failChapter2 := func() func(w http.ResponseWriter, r *http.Request) bool {
+		return func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path != "/api/v3/chapters/2/verses" {
+				return false
+			}
+			http.NotFound(w, r)
+			return true
+		}
+	}
+
+	keys := func(chapters ...int) []string {
+		var keys []string
+		for _, ch := range chapters {
+			for v := 1; v <= map[int]int{1: 7, 2: 10, 3: 3}[ch]; v++ {
+				keys = append(keys, verseKey(ch, v))
+			}
+		}
+		return keys
+	}
+
+	tests := []struct {
+		name         string
+		concurrency  int
+		intercept    func() func(w http.ResponseWriter, r *http.Request) bool
+		wantKeys     []string
+		wantChapters []int
+		wantErr      string
+	}{
+		{
+			name:         "later chapters fetched first",
+			concurrency:  3,
+			intercept:    lastFirst,
+			wantKeys:     keys(1, 2, 3),
+			wantChapters: []int{1, 2, 3},
+		},
+		{
+			name:         "one chapter at a time",
+			concurrency:  1,
+			wantKeys:     keys(1, 2, 3),
+			wantChapters: []int{1, 2, 3},
+		},
+		{
+			name:         "a chapter that fails",
+			concurrency:  3,
+			intercept:    failChapter2,
+			wantKeys:     keys(1),
+			wantChapters: []int{1},
+			wantErr:      "chapter 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &versesStub{chapters: chapters}
+			if tt.intercept != nil {
+				stub.intercept = tt.intercept()
+			}
+			c := newHandlerClient(t, stub, WithDefaultConcurrency(tt.concurrency))
+
+			var (
+				w       flushBuffer
+				updates []Progress
+			)
+			err := StreamNDJSON(ctx, c, &w, func(p Progress) { updates = append(updates, p) })
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want %s not found", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			dec := json.NewDecoder(&w.Buffer)
+			for dec.More() {
+				var v Verse
+				if err := dec.Decode(&v); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, v.VerseKey)
+			}
+			if !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("got verses %v, want %v", got, tt.wantKeys)
+			}
+
+			var gotChapters []int
+			for i, p := range updates {
+				if p.Done != i+1 || p.Total != len(chapters) {
+					t.Errorf("update %d is %d of %d done", i, p.Done, p.Total)
+				}
+				gotChapters = append(gotChapters, p.CurrentChapter)
+			}
+			if !reflect.DeepEqual(gotChapters, tt.wantChapters) {
+				t.Errorf("got progress of chapters %v, want %v", gotChapters, tt.wantChapters)
+			}
+			if w.flushes != len(tt.wantChapters) {
+				t.Errorf("flushed %d times, want once for each chapter written", w.flushes)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// JuzCount is the number of juz the quran is divided into.
+const JuzCount = 30
+
+func validateJuz(juzNumber int) error {
+	if juzNumber < 1 || juzNumber > JuzCount {
+		return fmt.Errorf("invalid juz %d: must be between 1 and %d", juzNumber, JuzCount)
+	}
+	return nil
+}
+
+// VersesByJuz returns the verses of the juz, in order. The page, limit and offset options
+// page through the juz's verses. When quran.com does not serve its by juz route, the
+// verses are gathered from the chapters in the juz's verse mapping and paged locally the
+// way quran.com pages them.
+func (c *Client) VersesByJuz(ctx context.Context, juzNumber int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	if err := validateJuz(juzNumber); err != nil {
+		return nil, err
+	}
+
+	var opts versesReqOpt
+	for _, optFn := range reqOpts {
+		opts = optFn(opts)
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := joinPath("verses", "by_juz", strconv.Itoa(juzNumber))
+	path, err := c.route("VersesByJuz", endpoint, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.versesQuery(c.c.Get(path), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	verses, _, err := c.doVerses(ctx, "VersesByJuz", req)
+	if errors.Is(err, ErrNotFound) {
+		return c.juzVersesFromMapping(ctx, juzNumber, opts, reqOpts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.prepareVerses(verses)
+
+	return verses, nil
+}
+
+// juzVersesFromMapping gathers the verses of the juz from the verses of the chapters in
+// its verse mapping, then applies the page, limit and offset of the options to them. The
+// offset skips that many verses ahead of the page, and the limit defaults to quran.com's
+// page size, as they do on quran.com.
+func (c *Client) juzVersesFromMapping(ctx context.Context, juzNumber int, opts versesReqOpt, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	juzzah, err := c.Juzzah(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var juz *Juz
+	for i := range juzzah {
+		if juzzah[i].JuzNumber == juzNumber {
+			juz = &juzzah[i]
+			break
+		}
+	}
+	if juz == nil {
+		return nil, fmt.Errorf("juz %d: %w", juzNumber, ErrNotFound)
+	}
+
+	// the offset is of the juz's verses, not of each chapter's.
+	chapterOpts := append(reqOpts[:len(reqOpts):len(reqOpts)], VersesOffset(0))
+
+	verses := []Verse{}
+	for _, m := range juz.VerseMapping {
+		chapterVerses, err := chapterVerses(ctx, c, m.ChapterID, m.EndVerse, chapterOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("juz %d: %w", juzNumber, err)
+		}
+		for _, v := range chapterVerses {
+			if v.VerseNumber >= m.StartVerse && v.VerseNumber <= m.EndVerse {
+				verses = append(verses, v)
+			}
+		}
+	}
+
+	limit := opts.Limit
+	if limit < 1 {
+		limit = defaultVersesLimit
+	}
+	var start int
+	if opts.Offset > 0 {
+		start = opts.Offset
+	}
+	if opts.Page > 1 {
+		start += (opts.Page - 1) * limit
+	}
+	if start >= len(verses) {
+		return []Verse{}, nil
+	}
+	end := start + limit
+	if end > len(verses) {
+		end = len(verses)
+	}
+	return verses[start:end], nil
+}
+
+// JuzStartPages returns the mushaf page each juz starts on, keyed by juz number, i.e.
+// juz 1 starts on page 1. The page of each juz's first verse is looked up through the
+// api, so a cached api only goes to quran.com once per verse. A juz whose page can not
+// be looked up is left out of the map, and its error is returned in a MultiError along
+// with the pages that were found.
+func JuzStartPages(ctx context.Context, api QuranAPI) (map[int]int, error) {
+	juzzah, err := api.Juzzah(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu    sync.Mutex
+		pages = make(map[int]int, len(juzzah))
+	)
+	_, err = batch(ctx, len(juzzah), apiConcurrency(api, 0), nil, nil, func(ctx context.Context, i int) error {
+		juz := juzzah[i]
+		first, ok := juzFirstVerse(juz)
+		if !ok {
+			return fmt.Errorf("juz %d: no verse mapping", juz.JuzNumber)
+		}
+
+		v, err := api.Verse(ctx, first.ChapterID, first.StartVerse, VersesIncludeWords(false))
+		if err != nil {
+			return fmt.Errorf("juz %d: %w", juz.JuzNumber, err)
+		}
+
+		mu.Lock()
+		pages[juz.JuzNumber] = v.PageNumber
+		mu.Unlock()
+		return nil
+	})
+	return pages, err
+}
+
+// VerseLocation is where a verse falls in the divisions of the quran and on the pages of
+// the mushaf.
+type VerseLocation struct {
+	Juz  int
+	Hizb int
+	Rub  int
+	Page int
+}
+
+// LocateVerse returns the juz, hizb, rub and page of the verse, i.e. for a jump to
+// location feature. The juz is found from the juz verse mappings, the rest from a single
+// verse fetched without its words. Both are looked up through the api provided, so a
+// cached api only goes to quran.com once per verse.
+func LocateVerse(ctx context.Context, api QuranAPI, key VerseKey) (VerseLocation, error) {
+	if _, err := ParseVerseKey(key.String()); err != nil {
+		return VerseLocation{}, err
+	}
+
+	juzzah, err := api.Juzzah(ctx)
+	if err != nil {
+		return VerseLocation{}, err
+	}
+
+	v, err := api.Verse(ctx, key.Chapter, key.Verse, VersesIncludeWords(false))
+	if err != nil {
+		return VerseLocation{}, fmt.Errorf("verse %s: %w", key, err)
+	}
+
+	loc := VerseLocation{
+		Juz:  v.JuzNumber,
+		Hizb: v.HizbNumber,
+		Rub:  v.RubNumber,
+		Page: v.PageNumber,
+	}
+	for _, juz := range juzzah {
+		for _, m := range juz.VerseMapping {
+			if m.ChapterID == key.Chapter && key.Verse >= m.StartVerse && key.Verse <= m.EndVerse {
+				loc.Juz = juz.JuzNumber
+			}
+		}
+	}
+	return loc, nil
+}
+
+// juzFirstVerse returns the mapping of the juz's first chapter, the verse mapping of the
+// api is not ordered.
+func juzFirstVerse(juz Juz) (JuzMapping, bool) {
+	if len(juz.VerseMapping) == 0 {
+		return JuzMapping{}, false
+	}
+
+	first := juz.VerseMapping[0]
+	for _, m := range juz.VerseMapping[1:] {
+		if m.ChapterID < first.ChapterID {
+			first = m
+		}
+	}
+	return first, true
+}
+
+// HizbBoundaries returns the keys of the verses where the hizb or rub changes from the verse
+// before them, in the order of the verses provided, i.e. to annotate the hizb quarters
+// within a chapter. The first verse is never a boundary, there is no verse before it to
+// compare to. Verses without a hizb and rub number are skipped.
+func HizbBoundaries(verses []Verse) []string {
+	var (
+		boundaries = make([]string, 0)
+		prev       *Verse
+	)
+	for i := range verses {
+		v := &verses[i]
+		if v.HizbNumber == 0 && v.RubNumber == 0 {
+			continue
+		}
+		if prev != nil && (v.HizbNumber != prev.HizbNumber || v.RubNumber != prev.RubNumber) {
+			boundaries = append(boundaries, v.VerseKey)
+		}
+		prev = v
+	}
+	return boundaries
+}
@@ -0,0 +1,379 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHizbBoundaries(t *testing.T) {
+	tests := []struct {
+		name   string
+		verses []Verse
+		want   []string
+	}{
+		{name: "no verses", want: []string{}},
+		{
+			name: "one quarter",
+			verses: []Verse{
+				{VerseKey: "1:1", HizbNumber: 1, RubNumber: 1},
+				{VerseKey: "1:2", HizbNumber: 1, RubNumber: 1},
+			},
+			want: []string{},
+		},
+		{
+			name: "quarters and hizbs",
+			verses: []Verse{
+				{VerseKey: "2:25", HizbNumber: 1, RubNumber: 1},
+				{VerseKey: "2:26", HizbNumber: 1, RubNumber: 2},
+				{VerseKey: "2:27", HizbNumber: 1, RubNumber: 2},
+				{VerseKey: "2:75", HizbNumber: 2, RubNumber: 5},
+			},
+			want: []string{"2:26", "2:75"},
+		},
+		{
+			name: "verses without divisions are skipped",
+			verses: []Verse{
+				{VerseKey: "2:25", HizbNumber: 1, RubNumber: 1},
+				{VerseKey: "2:26"},
+				{VerseKey: "2:27", HizbNumber: 1, RubNumber: 2},
+			},
+			want: []string{"2:27"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HizbBoundaries(tt.verses)
+			if got == nil {
+				t.Fatal("got nil, want a non-nil slice")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// juzs is the juz mapping the juz stubs serve, juz 1 spans two chapters, 1:1-7 and 2:1-3,
+// and juz 2 the 27 verses of 2:4-30.
+const juzs = `{"juzs": [
+	{"id": 1, "juz_number": 1, "verse_mapping": {"2": "1-3", "1": "1-7"}},
+	{"id": 2, "juz_number": 2, "verse_mapping": {"2": "4-30"}}
+]}`
+
+// juzStub serves the juz mapping of juzs, and the verses of the chapters in it. The by juz
+// route is not served unless byJuz is set.
+func juzStub(byJuz bool) *versesStub {
+	stub := &versesStub{chapters: []Chapter{
+		{ID: 1, ChapterNumber: 1, VersesCount: 7},
+		{ID: 2, ChapterNumber: 2, VersesCount: 286},
+	}}
+	stub.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Path {
+		case "/api/v3/juzs":
+			w.Write([]byte(juzs))
+			return true
+		case "/api/v3/verses/by_juz/1":
+			if !byJuz {
+				http.NotFound(w, r)
+				return true
+			}
+			writeJSON(w, map[string]interface{}{"verses": []Verse{stubVerse(stub.chapters[0], 1)}})
+			return true
+		}
+		return false
+	}
+	return stub
+}
+
+func TestClient_VersesByJuz(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("the by juz route is used when it is served", func(t *testing.T) {
+		stub := juzStub(true)
+		c := newHandlerClient(t, stub)
+
+		verses, err := c.VersesByJuz(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(verses) != 1 || verses[0].VerseKey != "1:1" {
+			t.Errorf("got %+v, want the verses of the route", verses)
+		}
+		if n := stub.requestCount("/api/v3/juzs"); n != 0 {
+			t.Errorf("fetched the juz mapping %d times", n)
+		}
+	})
+
+	t.Run("the mapping fallback pages the juz's verses", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			juz      int
+			reqOpts  []VersesReqOptFn
+			wantKeys []string
+		}{
+			{name: "all", wantKeys: []string{"1:1", "1:2", "1:3", "1:4", "1:5", "1:6", "1:7", "2:1", "2:2", "2:3"}},
+			{name: "first page", reqOpts: []VersesReqOptFn{VersesLimit(4)}, wantKeys: []string{"1:1", "1:2", "1:3", "1:4"}},
+			{name: "page across chapters", reqOpts: []VersesReqOptFn{VersesLimit(4), VersesPage(2)}, wantKeys: []string{"1:5", "1:6", "1:7", "2:1"}},
+			{name: "short last page", reqOpts: []VersesReqOptFn{VersesLimit(4), VersesPage(3)}, wantKeys: []string{"2:2", "2:3"}},
+			{name: "page past the end", reqOpts: []VersesReqOptFn{VersesLimit(4), VersesPage(4)}, wantKeys: []string{}},
+			{name: "offset", reqOpts: []VersesReqOptFn{VersesOffset(8)}, wantKeys: []string{"2:2", "2:3"}},
+			{name: "offset and limit", reqOpts: []VersesReqOptFn{VersesLimit(3), VersesOffset(2)}, wantKeys: []string{"1:3", "1:4", "1:5"}},
+			{name: "offset, limit and page", reqOpts: []VersesReqOptFn{VersesLimit(3), VersesPage(2), VersesOffset(2)}, wantKeys: []string{"1:6", "1:7", "2:1"}},
+			{name: "offset past the end", reqOpts: []VersesReqOptFn{VersesOffset(10)}, wantKeys: []string{}},
+			{
+				name:     "default limit",
+				juz:      2,
+				wantKeys: []string{"2:4", "2:5", "2:6", "2:7", "2:8", "2:9", "2:10", "2:11", "2:12", "2:13"},
+			},
+			{
+				name:     "page with the default limit",
+				juz:      2,
+				reqOpts:  []VersesReqOptFn{VersesPage(2)},
+				wantKeys: []string{"2:14", "2:15", "2:16", "2:17", "2:18", "2:19", "2:20", "2:21", "2:22", "2:23"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c := newHandlerClient(t, juzStub(false))
+
+				juz := tt.juz
+				if juz == 0 {
+					juz = 1
+				}
+				verses, err := c.VersesByJuz(ctx, juz, tt.reqOpts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys := make([]string, 0, len(verses))
+				for _, v := range verses {
+					keys = append(keys, v.VerseKey)
+				}
+				if !reflect.DeepEqual(keys, tt.wantKeys) {
+					t.Errorf("got %v, want %v", keys, tt.wantKeys)
+				}
+			})
+		}
+	})
+
+	t.Run("a juz missing from the mapping is not found", func(t *testing.T) {
+		c := newHandlerClient(t, juzStub(false))
+		if _, err := c.VersesByJuz(ctx, 3); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("an invalid juz sends no request", func(t *testing.T) {
+		stub := juzStub(false)
+		c := newHandlerClient(t, stub)
+		if _, err := c.VersesByJuz(ctx, 31); err == nil {
+			t.Error("got no error")
+		}
+		if n := stub.totalRequests(); n != 0 {
+			t.Errorf("sent %d requests", n)
+		}
+	})
+}
+
+// locationStub serves the juz mapping provided, and the verses of chapters 1 and 2 with a
+// location derived from their key. The page of a verse is its number plus its chapter,
+// its hizb and rub are its chapter, and its juz is left 0. The verse requests are
+// recorded.
+type locationStub struct {
+	juzs string
+
+	mu     sync.Mutex
+	verses []string
+}
+
+func (s *locationStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v3/juzs" {
+		w.Write([]byte(s.juzs))
+		return
+	}
+
+	var chapter, verse int
+	if _, err := fmt.Sscanf(r.URL.Path, "/api/v3/chapters/%d/verses/%d", &chapter, &verse); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.Lock()
+	s.verses = append(s.verses, r.URL.RequestURI())
+	s.mu.Unlock()
+	if chapter > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"verse": Verse{
+		ChapterID:   chapter,
+		VerseNumber: verse,
+		VerseKey:    verseKey(chapter, verse),
+		HizbNumber:  chapter,
+		RubNumber:   chapter,
+		PageNumber:  chapter + verse,
+	}})
+}
+
+func TestLocateVerse(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		key          VerseKey
+		want         VerseLocation
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{name: "first verse", key: VerseKey{Chapter: 1, Verse: 1}, want: VerseLocation{Juz: 1, Hizb: 1, Rub: 1, Page: 2}},
+		{name: "juz from the mapping", key: VerseKey{Chapter: 2, Verse: 5}, want: VerseLocation{Juz: 2, Hizb: 2, Rub: 2, Page: 7}},
+		{name: "verse outside the mapping", key: VerseKey{Chapter: 2, Verse: 100}, want: VerseLocation{Hizb: 2, Rub: 2, Page: 102}},
+		{name: "not found", key: VerseKey{Chapter: 3, Verse: 1}, wantErr: true, wantNotFound: true},
+		{name: "verse past the chapter", key: VerseKey{Chapter: 1, Verse: 8}, wantErr: true},
+		{name: "chapter zero", key: VerseKey{Verse: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &locationStub{juzs: juzs}
+			c := newHandlerClient(t, stub)
+
+			got, err := LocateVerse(ctx, c, tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+					t.Errorf("got error %v, want not found %t", err, tt.wantNotFound)
+				}
+				// malformed keys are rejected before any request.
+				if !tt.wantNotFound && len(stub.verses) != 0 {
+					t.Errorf("got verse requests %v", stub.verses)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if len(stub.verses) != 1 || !strings.Contains(stub.verses[0], "words=false") {
+				t.Errorf("got verse requests %v, want one without words", stub.verses)
+			}
+		})
+	}
+}
+
+func TestJuzStartPages(t *testing.T) {
+	// juz 3 starts in a chapter the stub does not serve, juz 4 has no mapping.
+	stub := &locationStub{juzs: `{"juzs": [
+		{"id": 1, "juz_number": 1, "verse_mapping": {"2": "1-3", "1": "1-7"}},
+		{"id": 2, "juz_number": 2, "verse_mapping": {"2": "4-10"}},
+		{"id": 3, "juz_number": 3, "verse_mapping": {"3": "1-5"}},
+		{"id": 4, "juz_number": 4, "verse_mapping": {}}
+	]}`}
+	c := newHandlerClient(t, stub)
+
+	pages, err := JuzStartPages(context.Background(), c)
+	if want := map[int]int{1: 2, 2: 6}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("got %v, want %v", pages, want)
+	}
+
+	var errs MultiError
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("got error %v, want the errors of juz 3 and 4", err)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("got error %v, want juz 3 not found", err)
+	}
+	if len(stub.verses) != 3 {
+		t.Errorf("got verse requests %v, want one for each juz with a mapping", stub.verses)
+	}
+}
+
+func TestClient_Juzzah(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		want    []JuzMapping
+		// wantStrictErr is whether a strict client errors on the mapping, a strict client
+		// that does not error returns the same mapping as a merging one.
+		wantStrictErr bool
+	}{
+		{
+			name:    "ordered by chapter",
+			mapping: `{"2": "1-141", "1": "1-7"}`,
+			want:    []JuzMapping{{ChapterID: 1, StartVerse: 1, EndVerse: 7}, {ChapterID: 2, StartVerse: 1, EndVerse: 141}},
+		},
+		{
+			name:          "duplicated chapter is merged",
+			mapping:       `{"2": "1-100", "02": "90-141"}`,
+			want:          []JuzMapping{{ChapterID: 2, StartVerse: 1, EndVerse: 141}},
+			wantStrictErr: true,
+		},
+		{
+			name:          "chapter that is not a number is skipped",
+			mapping:       `{"1": "1-7", "abc": "1-141"}`,
+			want:          []JuzMapping{{ChapterID: 1, StartVerse: 1, EndVerse: 7}},
+			wantStrictErr: true,
+		},
+		{
+			name:          "verses that are not numbers are skipped",
+			mapping:       `{"1": "1-7", "2": "x-141"}`,
+			want:          []JuzMapping{{ChapterID: 1, StartVerse: 1, EndVerse: 7}},
+			wantStrictErr: true,
+		},
+		{
+			name:          "verses that are not a range are skipped",
+			mapping:       `{"1": "1-7", "2": "141", "3": "1-2-3"}`,
+			want:          []JuzMapping{{ChapterID: 1, StartVerse: 1, EndVerse: 7}},
+			wantStrictErr: true,
+		},
+		{
+			name:          "reversed range is skipped",
+			mapping:       `{"1": "1-7", "2": "141-1"}`,
+			want:          []JuzMapping{{ChapterID: 1, StartVerse: 1, EndVerse: 7}},
+			wantStrictErr: true,
+		},
+		{
+			name:          "chapter zero is skipped",
+			mapping:       `{"0": "1-7"}`,
+			wantStrictErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		body := `{"juzs": [{"id": 1, "juz_number": 1, "verse_mapping": ` + tt.mapping + `}]}`
+		t.Run(tt.name, func(t *testing.T) {
+			juzzah, err := newTestClient(t, body).Juzzah(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(juzzah) != 1 || !reflect.DeepEqual(juzzah[0].VerseMapping, tt.want) {
+				t.Errorf("got %+v, want a mapping of %+v", juzzah, tt.want)
+			}
+		})
+		t.Run(tt.name+" strict", func(t *testing.T) {
+			juzzah, err := newTestClient(t, body, WithStrictJuzMapping()).Juzzah(context.Background())
+			if tt.wantStrictErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", juzzah)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(juzzah) != 1 || !reflect.DeepEqual(juzzah[0].VerseMapping, tt.want) {
+				t.Errorf("got %+v, want a mapping of %+v", juzzah, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package quranc
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// defaultMemoryCacheSize is the number of verse calls a MemoryCache keeps when no size is
+// provided.
+const defaultMemoryCacheSize = 128
+
+// MemoryCache is a QuranAPI middleware that keeps the verses of the most recently used
+// calls in memory, evicting the least recently used once it holds more than its size.
+// Chapters that are read often can be pinned, all their verses are kept in memory and
+// never evicted. It may be
+// layered over a bolt cache to skip the decode on hot chapters. Every method other than
+// Verses is passed through to the next QuranAPI.
+type MemoryCache struct {
+	QuranAPI
+
+	mu    sync.Mutex
+	size  int
+	lru   *list.List
+	items map[string]*list.Element
+	// pins are the verses of the pinned chapters, keyed by the pin key of the options
+	// they were pinned with, in verse order.
+	pins map[string][]Verse
+}
+
+type memoryCacheItem struct {
+	key    string
+	verses []Verse
+}
+
+// NewMemoryCache constructs a MemoryCache in front of the next QuranAPI that keeps at most
+// size unpinned verse calls in memory. A size less than 1 uses the default of 128.
+func NewMemoryCache(next QuranAPI, size int) *MemoryCache {
+	if size < 1 {
+		size = defaultMemoryCacheSize
+	}
+	return &MemoryCache{
+		QuranAPI: next,
+		size:     size,
+		lru:      list.New(),
+		items:    make(map[string]*list.Element),
+		pins:     make(map[string][]Verse),
+	}
+}
+
+func (m *MemoryCache) defaultConcurrency() int {
+	return apiConcurrency(m.QuranAPI, 0)
+}
+
+// Verses returns the chapter's verses from memory, fetching them from the next QuranAPI
+// on a miss.
+func (m *MemoryCache) Verses(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	var opts versesReqOpt
+	for _, o := range reqOpts {
+		opts = o(opts)
+	}
+
+	key, err := opts.key(chapterID)
+	if err != nil {
+		return m.QuranAPI.Verses(ctx, chapterID, reqOpts...)
+	}
+	pinKey, err := opts.pinKey(chapterID)
+	if err != nil {
+		return m.QuranAPI.Verses(ctx, chapterID, reqOpts...)
+	}
+
+	stats := callStatsFromContext(ctx)
+	if !opts.noCache {
+		verses, ok := m.getPinned(string(pinKey), opts)
+		if !ok {
+			verses, ok = m.get(string(key))
+		}
+		if ok {
+			if stats != nil {
+				stats.recordCache("Verses", true)
+			}
+			return verses, nil
+		}
+	}
+	if stats != nil {
+		stats.recordCache("Verses", false)
+	}
+
+	verses, err := m.QuranAPI.Verses(ctx, chapterID, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+	m.put(string(key), verses)
+	return append([]Verse(nil), verses...), nil
+}
+
+// PinChapters fetches all the verses of the chapters with the options provided and keeps
+// them in memory, they are never evicted. Later Verses calls for a pinned chapter made with
+// the same options are served from memory, whatever their page, limit and offset. The page
+// served is cut from the pinned verses the way the api pages them, a call without a limit
+// gets the api's default page of 10 verses. A chapter that fails to be fetched is not
+// pinned.
+func (m *MemoryCache) PinChapters(ctx context.Context, ids []int, reqOpts ...VersesReqOptFn) error {
+	var opts versesReqOpt
+	for _, o := range reqOpts {
+		opts = o(opts)
+	}
+
+	for _, id := range ids {
+		if err := validateChapter(id); err != nil {
+			return err
+		}
+
+		pinKey, err := opts.pinKey(id)
+		if err != nil {
+			return err
+		}
+
+		chapter, err := m.QuranAPI.Chapter(ctx, id)
+		if err != nil {
+			return err
+		}
+		verses, err := chapterVerses(ctx, m.QuranAPI, id, chapter.VersesCount, reqOpts...)
+		if err != nil {
+			return err
+		}
+		sortVersesByKey(verses)
+
+		m.mu.Lock()
+		m.pins[string(pinKey)] = verses
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// getPinned returns the page of the pinned chapter's verses the options select.
+func (m *MemoryCache) getPinned(pinKey string, opts versesReqOpt) ([]Verse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	verses, ok := m.pins[pinKey]
+	if !ok {
+		return nil, false
+	}
+
+	limit, page := opts.Limit, opts.Page
+	if limit < 1 {
+		limit = defaultVersesLimit
+	}
+	if page < 1 {
+		page = 1
+	}
+	start := (page-1)*limit + opts.Offset
+	if start >= len(verses) {
+		return []Verse{}, true
+	}
+	end := start + limit
+	if end > len(verses) {
+		end = len(verses)
+	}
+	return append([]Verse(nil), verses[start:end]...), true
+}
+
+func (m *MemoryCache) get(key string) ([]Verse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	m.lru.MoveToFront(el)
+	return append([]Verse(nil), el.Value.(*memoryCacheItem).verses...), true
+}
+
+func (m *MemoryCache) put(key string, verses []Verse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		el.Value.(*memoryCacheItem).verses = verses
+		m.lru.MoveToFront(el)
+		return
+	}
+	m.items[key] = m.lru.PushFront(&memoryCacheItem{key: key, verses: verses})
+	for m.lru.Len() > m.size {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryCacheItem).key)
+	}
+}
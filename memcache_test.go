@@ -0,0 +1,151 @@
+package quranc
+
+import (
+	"context"
+	"testing"
+)
+
+func verseNumbers(verses []Verse) []int {
+	numbers := make([]int, 0, len(verses))
+	for _, v := range verses {
+		numbers = append(numbers, v.VerseNumber)
+	}
+	return numbers
+}
+
+func TestMemoryCache_Verses(t *testing.T) {
+	t.Run("evicts the least recently used", func(t *testing.T) {
+		ctx := context.Background()
+		next := new(countingAPI)
+		m := NewMemoryCache(next, 2)
+
+		for _, id := range []int{1, 2, 1, 3} {
+			if _, err := m.Verses(ctx, id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if calls := next.callCount("Verses"); calls != 3 {
+			t.Fatalf("got %d upstream calls, want 3", calls)
+		}
+
+		// chapter 2 is the least recently used, chapter 1 was read after it.
+		if _, err := m.Verses(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		if calls := next.callCount("Verses"); calls != 3 {
+			t.Errorf("chapter 1 was evicted, got %d upstream calls, want 3", calls)
+		}
+		if _, err := m.Verses(ctx, 2); err != nil {
+			t.Fatal(err)
+		}
+		if calls := next.callCount("Verses"); calls != 4 {
+			t.Errorf("chapter 2 was not evicted, got %d upstream calls, want 4", calls)
+		}
+	})
+
+	t.Run("pinned chapters survive eviction", func(t *testing.T) {
+		ctx := context.Background()
+		next := new(countingAPI)
+		m := NewMemoryCache(next, 1)
+
+		if err := m.PinChapters(ctx, []int{1}); err != nil {
+			t.Fatal(err)
+		}
+		pinCalls := next.callCount("Verses")
+
+		for id := 2; id <= 10; id++ {
+			if _, err := m.Verses(ctx, id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := m.Verses(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		if calls := next.callCount("Verses") - pinCalls; calls != 9 {
+			t.Errorf("got %d upstream calls, want 9", calls)
+		}
+	})
+}
+
+func TestMemoryCache_PinChapters(t *testing.T) {
+	t.Run("serves every page from memory", func(t *testing.T) {
+		ctx := context.Background()
+		next := new(countingAPI)
+		m := NewMemoryCache(next, 0)
+
+		if err := m.PinChapters(ctx, []int{2}); err != nil {
+			t.Fatal(err)
+		}
+		pinCalls := next.callCount("Verses")
+
+		tests := []struct {
+			name    string
+			reqOpts []VersesReqOptFn
+			first   int
+			count   int
+		}{
+			{name: "no options", first: 1, count: 10},
+			{name: "page", reqOpts: []VersesReqOptFn{VersesPage(3)}, first: 21, count: 10},
+			{name: "limit", reqOpts: []VersesReqOptFn{VersesLimit(50)}, first: 1, count: 50},
+			{name: "page and limit", reqOpts: []VersesReqOptFn{VersesPage(2), VersesLimit(50)}, first: 51, count: 50},
+			{name: "offset", reqOpts: []VersesReqOptFn{VersesOffset(5), VersesLimit(3)}, first: 6, count: 3},
+			{name: "last page", reqOpts: []VersesReqOptFn{VersesPage(6), VersesLimit(50)}, first: 251, count: 36},
+			{name: "past the end", reqOpts: []VersesReqOptFn{VersesPage(7), VersesLimit(50)}, count: 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := m.Verses(ctx, 2, tt.reqOpts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != tt.count {
+					t.Fatalf("got %d verses, want %d", len(got), tt.count)
+				}
+				if tt.count > 0 && got[0].VerseNumber != tt.first {
+					t.Errorf("got verses %v, want them to start at %d", verseNumbers(got), tt.first)
+				}
+			})
+		}
+
+		if calls := next.callCount("Verses") - pinCalls; calls != 0 {
+			t.Errorf("got %d upstream calls for a pinned chapter, want 0", calls)
+		}
+	})
+
+	t.Run("other options are not served from the pin", func(t *testing.T) {
+		ctx := context.Background()
+		next := new(countingAPI)
+		m := NewMemoryCache(next, 0)
+
+		if err := m.PinChapters(ctx, []int{1}); err != nil {
+			t.Fatal(err)
+		}
+		pinCalls := next.callCount("Verses")
+
+		if _, err := m.Verses(ctx, 1, VersesLanguage("ur")); err != nil {
+			t.Fatal(err)
+		}
+		if calls := next.callCount("Verses") - pinCalls; calls != 1 {
+			t.Errorf("got %d upstream calls, want 1", calls)
+		}
+	})
+
+	t.Run("a failed chapter is not pinned", func(t *testing.T) {
+		ctx := context.Background()
+		next := &countingAPI{failChapter: 2}
+		m := NewMemoryCache(next, 0)
+
+		if err := m.PinChapters(ctx, []int{2}); err == nil {
+			t.Fatal("expected an error")
+		}
+		next.failChapter = 0
+
+		before := next.callCount("Verses")
+		if _, err := m.Verses(ctx, 2); err != nil {
+			t.Fatal(err)
+		}
+		if calls := next.callCount("Verses") - before; calls != 1 {
+			t.Errorf("got %d upstream calls, want 1", calls)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package quranc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Mushafs the page images are available in.
+const (
+	MushafMadani  = "madani"
+	MushafIndopak = "indopak"
+)
+
+// PageImageURL returns the url of the image of the mushaf page, i.e.
+// https://static.qurancdn.com/images/pages/madani/page001.png for the first page of the
+// madani mushaf. The page must be between 1 and 604 and the mushaf one of the Mushaf
+// constants. The base of the url is set with WithPageImageBaseURL.
+func (c *Client) PageImageURL(page int, mushaf string) (string, error) {
+	if err := validatePage(page); err != nil {
+		return "", err
+	}
+
+	switch mushaf {
+	case MushafMadani, MushafIndopak:
+	default:
+		return "", fmt.Errorf("invalid mushaf %q: must be one of %q or %q", mushaf, MushafMadani, MushafIndopak)
+	}
+
+	return fmt.Sprintf("%s/%s/page%03d.png", c.pageImageBaseURL, mushaf, page), nil
+}
+
+// VersesByPageGrouped returns the verses printed on the mushaf page grouped by chapter, as a
+// page may span the end of one chapter and the start of the next. The verses of each
+// chapter keep the order they are printed in. The page must be between 1 and 604.
+func (c *Client) VersesByPageGrouped(ctx context.Context, page int, reqOpts ...VersesReqOptFn) (map[int][]Verse, error) {
+	verses, err := c.VersesByPage(ctx, page, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	chapters := make(map[int][]Verse)
+	for _, v := range verses {
+		chapters[v.ChapterID] = append(chapters[v.ChapterID], v)
+	}
+	return chapters, nil
+}
+
+// VerseAllMushafs returns the verse as laid out in each of the quran.com mushafs provided,
+// keyed by mushaf id, for comparing a verse across layouts. One request is made per mushaf
+// with the api's default concurrency. The mushafs that fail are left out of the map and
+// their errors are returned together as a MultiError, along with the verses found.
+func VerseAllMushafs(ctx context.Context, api QuranAPI, chapterID, verseID int, mushafIDs []int) (map[int]Verse, error) {
+	var (
+		mu     sync.Mutex
+		verses = make(map[int]Verse, len(mushafIDs))
+	)
+	_, err := batch(ctx, len(mushafIDs), apiConcurrency(api, 0), nil, nil, func(ctx context.Context, i int) error {
+		v, err := api.Verse(ctx, chapterID, verseID, VersesMushaf(mushafIDs[i]))
+		if err != nil {
+			return fmt.Errorf("mushaf %d: %w", mushafIDs[i], err)
+		}
+
+		mu.Lock()
+		verses[mushafIDs[i]] = v
+		mu.Unlock()
+		return nil
+	})
+	return verses, err
+}
@@ -0,0 +1,182 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_PageImageURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ClientOptFn
+		page    int
+		mushaf  string
+		want    string
+		wantErr bool
+	}{
+		{name: "first madani page", page: 1, mushaf: MushafMadani, want: "https://static.qurancdn.com/images/pages/madani/page001.png"},
+		{name: "last indopak page", page: 604, mushaf: MushafIndopak, want: "https://static.qurancdn.com/images/pages/indopak/page604.png"},
+		{
+			name:   "base url",
+			opts:   []ClientOptFn{WithPageImageBaseURL("https://cdn.example.com/pages/")},
+			page:   42,
+			mushaf: MushafMadani,
+			want:   "https://cdn.example.com/pages/madani/page042.png",
+		},
+		{name: "page zero", page: 0, mushaf: MushafMadani, wantErr: true},
+		{name: "page past the mushaf", page: 605, mushaf: MushafMadani, wantErr: true},
+		{name: "unknown mushaf", page: 1, mushaf: "warsh", wantErr: true},
+		{name: "no mushaf", page: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.opts...).PageImageURL(tt.page, tt.mushaf)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_VersesByPageGrouped(t *testing.T) {
+	ctx := context.Background()
+
+	// the stub serves page 1 spanning the end of al-fatihah and the start of al-baqarah, out
+	// of order, and page 2 empty.
+	var requests int32
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var verses []Verse
+		if r.URL.Path == "/api/v3/verses/by_page/1" {
+			for _, key := range []string{"2:2", "1:7", "2:1", "1:6"} {
+				k, _ := ParseVerseKey(key)
+				verses = append(verses, Verse{ChapterID: k.Chapter, VerseNumber: k.Verse, VerseKey: key})
+			}
+		}
+		writeJSON(w, map[string]interface{}{"verses": verses})
+	})
+	c := newHandlerClient(t, h)
+
+	keys := func(chapters map[int][]Verse) map[int][]string {
+		got := make(map[int][]string, len(chapters))
+		for ch, verses := range chapters {
+			for _, v := range verses {
+				got[ch] = append(got[ch], v.VerseKey)
+			}
+		}
+		return got
+	}
+
+	tests := []struct {
+		name    string
+		page    int
+		want    map[int][]string
+		wantErr bool
+	}{
+		{name: "page spanning two chapters", page: 1, want: map[int][]string{1: {"1:6", "1:7"}, 2: {"2:1", "2:2"}}},
+		{name: "empty page", page: 2, want: map[int][]string{}},
+		{name: "page zero", page: 0, wantErr: true},
+		{name: "page past the mushaf", page: 605, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+
+			got, err := c.VersesByPageGrouped(ctx, tt.page)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", keys(got))
+				}
+				if n := atomic.LoadInt32(&requests); n != 0 {
+					t.Errorf("sent %d requests for an invalid page", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(keys(got), tt.want) {
+				t.Errorf("got %v, want %v", keys(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestVerseAllMushafs(t *testing.T) {
+	ctx := context.Background()
+
+	// the stub lays 2:255 out on a page numbered after the mushaf, and fails mushaf 3.
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/chapters/2/verses/255" {
+			t.Errorf("got request to %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		mushaf, _ := strconv.Atoi(r.URL.Query().Get("mushaf"))
+		if mushaf == 3 {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"verse": Verse{ChapterID: 2, VerseNumber: 255, VerseKey: "2:255", PageNumber: 40 + mushaf}})
+	})
+	c := newHandlerClient(t, h)
+
+	pages := func(verses map[int]Verse) map[int]int {
+		got := make(map[int]int, len(verses))
+		for mushaf, v := range verses {
+			got[mushaf] = v.PageNumber
+		}
+		return got
+	}
+
+	tests := []struct {
+		name      string
+		mushafIDs []int
+		want      map[int]int
+		wantErrs  int
+	}{
+		{name: "every mushaf", mushafIDs: []int{1, 2, 5}, want: map[int]int{1: 41, 2: 42, 5: 45}},
+		{name: "a mushaf that fails", mushafIDs: []int{1, 3, 5}, want: map[int]int{1: 41, 5: 45}, wantErrs: 1},
+		{name: "no mushafs", want: map[int]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerseAllMushafs(ctx, c, 2, 255, tt.mushafIDs)
+			if !reflect.DeepEqual(pages(got), tt.want) {
+				t.Errorf("got pages %v, want %v", pages(got), tt.want)
+			}
+
+			if tt.wantErrs == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var errs MultiError
+			if !errors.As(err, &errs) || len(errs) != tt.wantErrs {
+				t.Fatalf("got error %v, want %d errors", err, tt.wantErrs)
+			}
+			if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "mushaf 3") {
+				t.Errorf("got error %v, want mushaf 3 not found", err)
+			}
+		})
+	}
+}
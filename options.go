@@ -0,0 +1,51 @@
+package quranc
+
+import (
+	"context"
+	"fmt"
+)
+
+// AllOptions bundles the options endpoints of quran.com an app needs to bootstrap.
+type AllOptions struct {
+	Languages    []Language
+	Translations []Translation
+	Tafsiraat    []Tafsir
+	Recitations  []Recitation
+}
+
+// Options fetches the languages, translations, tafsiraat and recitations concurrently
+// through the api, so when the api is cached each is cached individually. The options
+// that fail are left nil and their errors are returned together as a MultiError, along
+// with the options that were fetched.
+func Options(ctx context.Context, api QuranAPI, reqOpts ...ReqOptFn) (AllOptions, error) {
+	var all AllOptions
+	fetches := []struct {
+		name  string
+		fetch func(ctx context.Context) error
+	}{
+		{"languages", func(ctx context.Context) (err error) {
+			all.Languages, err = api.Languages(ctx, reqOpts...)
+			return err
+		}},
+		{"translations", func(ctx context.Context) (err error) {
+			all.Translations, err = api.Translations(ctx, reqOpts...)
+			return err
+		}},
+		{"tafsiraat", func(ctx context.Context) (err error) {
+			all.Tafsiraat, err = api.Tafsiraat(ctx, reqOpts...)
+			return err
+		}},
+		{"recitations", func(ctx context.Context) (err error) {
+			all.Recitations, err = api.Recitations(ctx, reqOpts...)
+			return err
+		}},
+	}
+
+	_, err := batch(ctx, len(fetches), len(fetches), nil, nil, func(ctx context.Context, i int) error {
+		if err := fetches[i].fetch(ctx); err != nil {
+			return fmt.Errorf("%s: %w", fetches[i].name, err)
+		}
+		return nil
+	})
+	return all, err
+}
@@ -0,0 +1,129 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	ctx := context.Background()
+
+	// optionsHandler serves each options list with a single option, failing the lists
+	// provided. It records the language each list was asked for in.
+	optionsHandler := func(failing ...string) (http.HandlerFunc, func() map[string]string) {
+		var (
+			mu        sync.Mutex
+			languages = map[string]string{}
+		)
+		h := func(w http.ResponseWriter, r *http.Request) {
+			list := strings.TrimPrefix(r.URL.Path, "/api/v3/options/")
+			mu.Lock()
+			languages[list] = r.URL.Query().Get("language")
+			mu.Unlock()
+
+			for _, f := range failing {
+				if f == list {
+					http.NotFound(w, r)
+					return
+				}
+			}
+			switch list {
+			case "languages":
+				writeJSON(w, map[string]interface{}{"languages": []Language{{ID: 38, Name: "english"}}})
+			case "translations":
+				writeJSON(w, map[string]interface{}{"translations": []Translation{{ID: 20}}})
+			case "tafsirs":
+				writeJSON(w, map[string]interface{}{"tafsirs": []Tafsir{{ID: 169}}})
+			case "recitations":
+				writeJSON(w, map[string]interface{}{"recitations": []Recitation{{ID: 7}}})
+			default:
+				t.Errorf("got request to %s", r.URL.Path)
+				http.NotFound(w, r)
+			}
+		}
+		return h, func() map[string]string {
+			mu.Lock()
+			defer mu.Unlock()
+			return languages
+		}
+	}
+
+	// fetched returns the names of the options lists that were fetched.
+	fetched := func(all AllOptions) []string {
+		var names []string
+		if all.Languages != nil {
+			names = append(names, "languages")
+		}
+		if all.Translations != nil {
+			names = append(names, "translations")
+		}
+		if all.Tafsiraat != nil {
+			names = append(names, "tafsirs")
+		}
+		if all.Recitations != nil {
+			names = append(names, "recitations")
+		}
+		return names
+	}
+
+	tests := []struct {
+		name        string
+		failing     []string
+		wantFetched []string
+		wantErrs    []string
+	}{
+		{name: "all options", wantFetched: []string{"languages", "translations", "tafsirs", "recitations"}},
+		{name: "languages fail", failing: []string{"languages"}, wantFetched: []string{"translations", "tafsirs", "recitations"}, wantErrs: []string{"languages"}},
+		{name: "translations fail", failing: []string{"translations"}, wantFetched: []string{"languages", "tafsirs", "recitations"}, wantErrs: []string{"translations"}},
+		{name: "tafsiraat fail", failing: []string{"tafsirs"}, wantFetched: []string{"languages", "translations", "recitations"}, wantErrs: []string{"tafsiraat"}},
+		{name: "recitations fail", failing: []string{"recitations"}, wantFetched: []string{"languages", "translations", "tafsirs"}, wantErrs: []string{"recitations"}},
+		{
+			name:     "every list fails",
+			failing:  []string{"languages", "translations", "tafsirs", "recitations"},
+			wantErrs: []string{"languages", "translations", "tafsiraat", "recitations"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, languages := optionsHandler(tt.failing...)
+			c := newHandlerClient(t, h)
+
+			all, err := Options(ctx, c, LanguageID(38))
+			if got := fetched(all); strings.Join(got, ",") != strings.Join(tt.wantFetched, ",") {
+				t.Errorf("got %v fetched, want %v", got, tt.wantFetched)
+			}
+			for list, lang := range languages() {
+				if lang != "38" {
+					t.Errorf("got %s asked for in language %q, want 38", list, lang)
+				}
+			}
+			if n := len(languages()); n != 4 {
+				t.Errorf("got %d lists requested, want 4", n)
+			}
+
+			if len(tt.wantErrs) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var errs MultiError
+			if !errors.As(err, &errs) || len(errs) != len(tt.wantErrs) {
+				t.Fatalf("got error %v, want %d errors", err, len(tt.wantErrs))
+			}
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("got error %v, want ErrNotFound", err)
+			}
+			for _, name := range tt.wantErrs {
+				if !strings.Contains(err.Error(), name+":") {
+					t.Errorf("got error %v, want one for %s", err, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package quranc
+
+import "sync"
+
+// Progress reports how far along a long running operation, such as DownloadAll, is.
+// Done counts up to Total, and CurrentChapter is the chapter most recently completed
+// when the operation works through chapters.
+type Progress struct {
+	Done           int
+	Total          int
+	CurrentChapter int
+}
+
+// ProgressFunc receives progress updates from the long running operations it is passed
+// to, DownloadAll, WarmChapterTafsir, ExportChapters and StreamNDJSON. Updates are
+// delivered one at a time and in order, so Done never goes backwards between calls, and
+// the last update is delivered before the operation returns. Operations working
+// concurrently never wait on the function, updates made while it runs are coalesced into
+// the latest one. A nil ProgressFunc is never called.
+type ProgressFunc func(Progress)
+
+// report calls fn with the progress, when fn is not nil.
+func (fn ProgressFunc) report(p Progress) {
+	if fn != nil {
+		fn(p)
+	}
+}
+
+// progressReporter calls a ProgressFunc from a goroutine of its own with the latest
+// progress it is updated with, so the workers of a batch neither wait on the function nor
+// call it while holding a lock of theirs.
+type progressReporter struct {
+	fn      ProgressFunc
+	updated chan struct{}
+	done    chan struct{}
+
+	mu      sync.Mutex
+	latest  Progress
+	pending bool
+}
+
+// newProgressReporter starts a reporter calling fn, a nil fn returns a nil reporter that
+// reports nothing.
+func newProgressReporter(fn ProgressFunc) *progressReporter {
+	if fn == nil {
+		return nil
+	}
+
+	r := &progressReporter{
+		fn:      fn,
+		updated: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// update sets the latest progress, it never waits on the ProgressFunc.
+func (r *progressReporter) update(p Progress) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	r.latest, r.pending = p, true
+	r.mu.Unlock()
+
+	select {
+	case r.updated <- struct{}{}:
+	default:
+		// the reporter has yet to pick up an earlier update, it reads the latest.
+	}
+}
+
+func (r *progressReporter) run() {
+	defer close(r.done)
+	for range r.updated {
+		r.mu.Lock()
+		p, pending := r.latest, r.pending
+		r.pending = false
+		r.mu.Unlock()
+
+		if pending {
+			r.fn(p)
+		}
+	}
+}
+
+// close delivers the latest progress, if it has not been, and stops the reporter.
+func (r *progressReporter) close() {
+	if r == nil {
+		return
+	}
+	close(r.updated)
+	<-r.done
+}
@@ -0,0 +1,89 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// totalVerses is the number of verses in the quran.
+const totalVerses = 6236
+
+// lockedRand is a rand.Rand safe for concurrent use.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// newLockedRand returns a lockedRand reading from src, or from a source seeded with the
+// clock's time when src is nil.
+func newLockedRand(src rand.Source, now func() time.Time) *lockedRand {
+	if src == nil {
+		src = rand.NewSource(now().UnixNano())
+	}
+	return &lockedRand{r: rand.New(src)}
+}
+
+func (l *lockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// RandomVerse returns a random verse, i.e. for a verse of the day. When quran.com does
+// not serve its random verse route, a verse is picked uniformly from every verse of the
+// quran and fetched with Verse. The pick is made from the client's source of randomness,
+// set WithRandSource for a deterministic pick.
+func (c *Client) RandomVerse(ctx context.Context, reqOpts ...VersesReqOptFn) (Verse, error) {
+	var opts versesReqOpt
+	for _, optFn := range reqOpts {
+		opts = optFn(opts)
+	}
+	if err := opts.validate(); err != nil {
+		return Verse{}, err
+	}
+
+	endpoint := joinPath("verses", "random")
+	path, err := c.route("RandomVerse", endpoint, endpoint)
+	if err != nil {
+		return Verse{}, err
+	}
+	req, err := c.versesQuery(c.c.Get(path), opts)
+	if err != nil {
+		return Verse{}, err
+	}
+
+	verse, err := c.doVerse(ctx, "RandomVerse", req)
+	if errors.Is(err, ErrNotFound) {
+		chapterID, verseNumber := randomVerseKey(c.rand)
+		return c.Verse(ctx, chapterID, verseNumber, reqOpts...)
+	}
+	if err != nil {
+		return Verse{}, err
+	}
+
+	c.prepareVerses([]Verse{verse})
+
+	return verse, nil
+}
+
+// randomVerseKey picks a verse uniformly from every verse of the quran, using the verse
+// counts of the embedded chapters.
+func randomVerseKey(r *lockedRand) (chapterID, verseNumber int) {
+	n := r.Intn(totalVerses)
+	for _, ch := range StaticChapters() {
+		if n < ch.VersesCount {
+			return ch.ID, n + 1
+		}
+		n -= ch.VersesCount
+	}
+	return 114, 6
+}
@@ -0,0 +1,37 @@
+package quranc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_RandomVerse(t *testing.T) {
+	t.Run("the fallback pick is seeded from the clock", func(t *testing.T) {
+		// pick returns the verse the client falls back to when the random route is not
+		// served.
+		pick := func(t *testing.T, clock *fakeClock) string {
+			t.Helper()
+			var picked string
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/verses/random") {
+					http.NotFound(w, r)
+					return
+				}
+				picked = r.URL.Path
+				w.Write([]byte(`{"verse": {}}`))
+			})
+			c := newHandlerClient(t, h, WithClientClock(clock.now))
+			if _, err := c.RandomVerse(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			return picked
+		}
+
+		first, second := pick(t, newFakeClock()), pick(t, newFakeClock())
+		if first == "" || first != second {
+			t.Errorf("got picks %q and %q, want the same verse", first, second)
+		}
+	})
+}
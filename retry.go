@@ -0,0 +1,135 @@
+package quranc
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryDelay caps the delay between retries, so a long run of failures does not leave a
+// call waiting minutes between attempts.
+const maxRetryDelay = 10 * time.Second
+
+// retryDoer retries idempotent requests that failed with a network error or a 5xx or 429
+// response, up to maxAttempts attempts in total, waiting the backoff's delay in between.
+// It gives up early when the request's context is done, returning the context's error.
+// Maintenance 503s are not retried, maintenance outlasts any retry.
+func retryDoer(next Doer, maxAttempts int, backoff Backoff) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			return next.Do(r)
+		}
+
+		ctx := r.Context()
+		for attempt := 0; ; attempt++ {
+			setResponseErr(ctx, nil)
+			resp, err := next.Do(r)
+			if attempt+1 >= maxAttempts || ctx.Err() != nil || !retryable(resp, err) || responseErrFrom(ctx) == ErrMaintenance {
+				return resp, err
+			}
+			if resp != nil {
+				io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxMaintenanceBody))
+				resp.Body.Close()
+			}
+
+			timer := time.NewTimer(backoff.Next(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				// the slot still holds the error of the failed attempt, the context's
+				// error replaces it so it surfaces from the call unwrapped.
+				setResponseErr(ctx, ctx.Err())
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	})
+}
+
+// retryable reports whether the outcome of a request is a transient failure worth
+// retrying, a network error or a server error. Other 4xx responses are never retried, the
+// request would fail the same way again.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// RateLimitError is returned when quran.com keeps responding 429 Too Many Requests after
+// the wait it asked for in its Retry-After header.
+type RateLimitError struct {
+	// RetryAfter is the wait the last response asked for, zero when it sent no
+	// Retry-After header.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter <= 0 {
+		return "rate limited by quran.com"
+	}
+	return fmt.Sprintf("rate limited by quran.com, retry after %s", e.RetryAfter)
+}
+
+// retryAfterDoer retries a 429 once after the wait in its Retry-After header. A wait
+// that would run past the request's deadline is not waited out. When the request is
+// still rate limited a *RateLimitError is set on the response error slot. Retry-After
+// dates are resolved against the clock, the deadline against the real time it is set in.
+func retryAfterDoer(next Doer, now func() time.Time) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := next.Do(r)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+
+		ctx := r.Context()
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now())
+		if deadline, hasDeadline := ctx.Deadline(); !ok || hasDeadline && time.Until(deadline) < wait {
+			setResponseErr(ctx, &RateLimitError{RetryAfter: wait})
+			return resp, nil
+		}
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxMaintenanceBody))
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		resp, err = next.Do(r)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+		wait, _ = parseRetryAfter(resp.Header.Get("Retry-After"), now())
+		setResponseErr(ctx, &RateLimitError{RetryAfter: wait})
+		return resp, nil
+	})
+}
+
+// parseRetryAfter parses a Retry-After header, sent as either a number of seconds or an
+// http date. Dates in the past are a wait of zero.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if wait := t.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
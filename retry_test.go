@@ -0,0 +1,284 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_retryAfter(t *testing.T) {
+	clock := newFakeClock()
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		// limited is the number of requests answered 429 before the response succeeds.
+		limited      int
+		wantRequests int32
+		wantWait     time.Duration
+		wantErr      bool
+	}{
+		{
+			name:         "a date at the clock's time is retried at once",
+			retryAfter:   clock.now().Format(http.TimeFormat),
+			limited:      1,
+			wantRequests: 2,
+		},
+		{
+			name:         "zero seconds is retried at once",
+			retryAfter:   "0",
+			limited:      1,
+			wantRequests: 2,
+		},
+		{
+			name:         "a date past the deadline is not waited out",
+			retryAfter:   clock.now().Add(time.Hour).Format(http.TimeFormat),
+			limited:      1,
+			wantRequests: 1,
+			wantWait:     time.Hour,
+			wantErr:      true,
+		},
+		{
+			name:         "seconds past the deadline are not waited out",
+			retryAfter:   "120",
+			limited:      1,
+			wantRequests: 1,
+			wantWait:     2 * time.Minute,
+			wantErr:      true,
+		},
+		{
+			name:         "still limited after the retry",
+			retryAfter:   "0",
+			limited:      2,
+			wantRequests: 2,
+			wantErr:      true,
+		},
+		{
+			name:         "no header is not retried",
+			limited:      1,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= int32(tt.limited) {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(`{"chapters": []}`))
+			})
+			c := newHandlerClient(t, h, WithClientClock(clock.now))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			_, err := c.Chapters(ctx)
+			if n := atomic.LoadInt32(&requests); n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var rateErr *RateLimitError
+			if !errors.As(err, &rateErr) {
+				t.Fatalf("got error %v, want a *RateLimitError", err)
+			}
+			if rateErr.RetryAfter != tt.wantWait {
+				t.Errorf("got retry after %s, want %s", rateErr.RetryAfter, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestClient_retry(t *testing.T) {
+	// maintenance is the body quran.com sends with its maintenance 503s.
+	const maintenance = `{"maintenance": true, "message": "down for maintenance"}`
+
+	// closeConn fails the request with a network error, the connection is closed before
+	// any response is written.
+	closeConn := func(w http.ResponseWriter) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			panic(err)
+		}
+		conn.Close()
+	}
+
+	tests := []struct {
+		name     string
+		attempts int
+		// respond writes the response to the request numbered n, from 1.
+		respond      func(w http.ResponseWriter, n int32)
+		wantRequests int32
+		wantStatus   int
+		wantErr      error
+	}{
+		{
+			name:     "a 500 is retried",
+			attempts: 3,
+			respond: func(w http.ResponseWriter, n int32) {
+				if n == 1 {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Write([]byte(`{"chapters": []}`))
+			},
+			wantRequests: 2,
+		},
+		{
+			name:     "a network error is retried",
+			attempts: 3,
+			respond: func(w http.ResponseWriter, n int32) {
+				if n == 1 {
+					closeConn(w)
+					return
+				}
+				w.Write([]byte(`{"chapters": []}`))
+			},
+			wantRequests: 2,
+		},
+		{
+			name:         "attempts run out",
+			attempts:     3,
+			respond:      func(w http.ResponseWriter, n int32) { w.WriteHeader(http.StatusBadGateway) },
+			wantRequests: 3,
+			wantStatus:   http.StatusBadGateway,
+		},
+		{
+			name:         "a 404 is not retried",
+			attempts:     3,
+			respond:      func(w http.ResponseWriter, n int32) { w.WriteHeader(http.StatusNotFound) },
+			wantRequests: 1,
+			wantErr:      ErrNotFound,
+		},
+		{
+			name:     "maintenance is not retried",
+			attempts: 3,
+			respond: func(w http.ResponseWriter, n int32) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(maintenance))
+			},
+			wantRequests: 1,
+			wantErr:      ErrMaintenance,
+		},
+		{
+			name:         "a single attempt is not retried",
+			attempts:     1,
+			respond:      func(w http.ResponseWriter, n int32) { w.WriteHeader(http.StatusInternalServerError) },
+			wantRequests: 1,
+			wantStatus:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.respond(w, atomic.AddInt32(&requests, 1))
+			})
+			c := newHandlerClient(t, h, WithRetry(tt.attempts, time.Millisecond))
+
+			_, err := c.Chapters(context.Background())
+			if n := atomic.LoadInt32(&requests); n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got error %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantStatus != 0:
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus {
+					t.Errorf("got error %v, want an *APIError with status %d", err, tt.wantStatus)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+		})
+	}
+
+	t.Run("waiting stops when the context is done", func(t *testing.T) {
+		var requests int32
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		c := newHandlerClient(t, h, WithRetry(3, time.Hour))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := c.Chapters(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v, want context.DeadlineExceeded", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("returned after %s", elapsed)
+		}
+		if n := atomic.LoadInt32(&requests); n != 1 {
+			t.Errorf("sent %d requests, want 1", n)
+		}
+	})
+}
+
+// countingSource is a rand.Source counting the numbers drawn from it.
+type countingSource struct {
+	rand.Source
+	draws int32
+}
+
+func (s *countingSource) Int63() int64 {
+	atomic.AddInt32(&s.draws, 1)
+	return s.Source.Int63()
+}
+
+func TestClient_retryJitterSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int32
+		wantDraws bool
+	}{
+		{name: "retries draw from the source", failures: 2, wantDraws: true},
+		{name: "no retries draw nothing", failures: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= tt.failures {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Write([]byte(`{"chapters": []}`))
+			})
+			src := &countingSource{Source: rand.NewSource(1)}
+			c := newHandlerClient(t, h, WithRetry(3, time.Millisecond), WithRandSource(src))
+
+			if _, err := c.Chapters(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if draws := atomic.LoadInt32(&src.draws); (draws >= tt.failures && draws > 0) != tt.wantDraws {
+				t.Errorf("got %d draws from the source for %d retries", draws, tt.failures)
+			}
+		})
+	}
+}
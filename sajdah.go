@@ -0,0 +1,91 @@
+package quranc
+
+import (
+	"context"
+	"sort"
+)
+
+// SajdahType is whether the prostration of a sajdah verse is obligatory or recommended.
+type SajdahType string
+
+// Types of sajdah, SajdahNone is returned for verses that are not sajdah verses.
+const (
+	SajdahNone        SajdahType = ""
+	SajdahRecommended SajdahType = "recommended"
+	SajdahObligatory  SajdahType = "obligatory"
+)
+
+// sajdahVerses are the 15 sajdah verses of the Qur'an, keyed by verse key. They never
+// change, so they are looked up here rather than fetched. The types match the values of
+// the api's Verse.Sajdah.
+var sajdahVerses = map[string]SajdahType{
+	"7:206":  SajdahRecommended,
+	"13:15":  SajdahRecommended,
+	"16:50":  SajdahRecommended,
+	"17:109": SajdahRecommended,
+	"19:58":  SajdahRecommended,
+	"22:18":  SajdahRecommended,
+	"22:77":  SajdahRecommended,
+	"25:60":  SajdahRecommended,
+	"27:26":  SajdahRecommended,
+	"32:15":  SajdahObligatory,
+	"38:24":  SajdahRecommended,
+	"41:38":  SajdahObligatory,
+	"53:62":  SajdahObligatory,
+	"84:21":  SajdahRecommended,
+	"96:19":  SajdahObligatory,
+}
+
+// IsSajdahVerse reports whether the verse with the key, i.e. "32:15", is a sajdah verse and
+// its type, without a request to quran.com. The key must be a valid verse key.
+func IsSajdahVerse(ctx context.Context, key string) (bool, SajdahType, error) {
+	chapterID, verseNumber, err := parseVerseKey(key)
+	if err != nil {
+		return false, SajdahNone, err
+	}
+
+	sajdah, ok := sajdahVerses[verseKey(chapterID, verseNumber)]
+	return ok, sajdah, nil
+}
+
+// SajdahVerses returns the 15 sajdah verses, fetched through the api with the options
+// provided and ordered by their sajdah number. The verses are looked up from the known
+// sajdah keys, quran.com has no route listing them. A verse the api returns without its
+// sajdah type has it filled in from the known keys.
+func SajdahVerses(ctx context.Context, api QuranAPI, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	keys := make([]VerseKey, 0, len(sajdahVerses))
+	for key := range sajdahVerses {
+		k, err := ParseVerseKey(key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Chapter != keys[j].Chapter {
+			return keys[i].Chapter < keys[j].Chapter
+		}
+		return keys[i].Verse < keys[j].Verse
+	})
+
+	verses := make([]Verse, len(keys))
+	_, err := batch(ctx, len(keys), apiConcurrency(api, 0), nil, nil, func(ctx context.Context, i int) error {
+		v, err := api.Verse(ctx, keys[i].Chapter, keys[i].Verse, reqOpts...)
+		if err != nil {
+			return err
+		}
+		if v.Sajdah == "" {
+			v.Sajdah = string(sajdahVerses[keys[i].String()])
+		}
+		verses[i] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(verses, func(i, j int) bool {
+		return verses[i].SajdahNumber < verses[j].SajdahNumber
+	})
+	return verses, nil
+}
@@ -0,0 +1,138 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestIsSajdahVerse(t *testing.T) {
+	tests := []struct {
+		key        string
+		wantSajdah bool
+		wantType   SajdahType
+		wantErr    bool
+	}{
+		{key: "7:206", wantSajdah: true, wantType: SajdahRecommended},
+		{key: "32:15", wantSajdah: true, wantType: SajdahObligatory},
+		{key: "96:19", wantSajdah: true, wantType: SajdahObligatory},
+		{key: "1:1"},
+		{key: "32:14"},
+		{key: "96:18"},
+		{key: "32:15:1", wantErr: true},
+		{key: "0:1", wantErr: true},
+		{key: "115:1", wantErr: true},
+		{key: "96:20", wantErr: true},
+		{key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			sajdah, typ, err := IsSajdahVerse(context.Background(), tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %t %q, want an error", sajdah, typ)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sajdah != tt.wantSajdah || typ != tt.wantType {
+				t.Errorf("got %t %q, want %t %q", sajdah, typ, tt.wantSajdah, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestSajdahVerses(t *testing.T) {
+	ctx := context.Background()
+
+	// numbered serves the sajdah verses with the sajdah number and type the api sends, 53:62
+	// with the highest number and 7:206 with the type flipped.
+	numbered := func(w http.ResponseWriter, r *http.Request) bool {
+		numbers := map[string]int{"/api/v3/chapters/7/verses/206": 1, "/api/v3/chapters/53/verses/62": 16}
+		n, ok := numbers[r.URL.Path]
+		if !ok {
+			return false
+		}
+		v := Verse{ChapterID: 7, VerseNumber: 206, VerseKey: "7:206", SajdahNumber: n, Sajdah: string(SajdahObligatory)}
+		if n == 16 {
+			v = Verse{ChapterID: 53, VerseNumber: 62, VerseKey: "53:62", SajdahNumber: n}
+		}
+		writeJSON(w, map[string]interface{}{"verse": v})
+		return true
+	}
+	missing := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/api/v3/chapters/41/verses/38" {
+			return false
+		}
+		http.NotFound(w, r)
+		return true
+	}
+
+	tests := []struct {
+		name      string
+		intercept func(w http.ResponseWriter, r *http.Request) bool
+		wantFirst string
+		wantLast  string
+		wantType  map[string]SajdahType
+		wantErr   error
+	}{
+		{
+			name:      "ordered by key without sajdah numbers",
+			wantFirst: "7:206",
+			wantLast:  "96:19",
+			wantType:  map[string]SajdahType{"7:206": SajdahRecommended, "32:15": SajdahObligatory},
+		},
+		{
+			name:      "ordered by sajdah number",
+			intercept: numbered,
+			wantFirst: "13:15",
+			wantLast:  "53:62",
+			wantType:  map[string]SajdahType{"7:206": SajdahObligatory, "53:62": SajdahObligatory},
+		},
+		{
+			name:      "a verse that fails",
+			intercept: missing,
+			wantErr:   ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &versesStub{chapters: StaticChapters(), intercept: tt.intercept}
+			c := newHandlerClient(t, stub)
+
+			verses, err := SajdahVerses(ctx, c)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(verses) != len(sajdahVerses) {
+				t.Fatalf("got %d verses, want %d", len(verses), len(sajdahVerses))
+			}
+			if first, last := verses[0].VerseKey, verses[len(verses)-1].VerseKey; first != tt.wantFirst || last != tt.wantLast {
+				t.Errorf("got verses from %s to %s, want %s to %s", first, last, tt.wantFirst, tt.wantLast)
+			}
+			for _, v := range verses {
+				if want, ok := tt.wantType[v.VerseKey]; ok && SajdahType(v.Sajdah) != want {
+					t.Errorf("got %s type %q, want %q", v.VerseKey, v.Sajdah, want)
+				}
+				if v.Sajdah == "" {
+					t.Errorf("got %s without its sajdah type", v.VerseKey)
+				}
+			}
+			if n := stub.totalRequests(); n != len(sajdahVerses) {
+				t.Errorf("sent %d requests, want one for each sajdah verse", n)
+			}
+		})
+	}
+}
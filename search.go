@@ -0,0 +1,126 @@
+package quranc
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// normalize sets the results, and the words and translations of each result, to empty
+// slices when the response left them out.
+func (r *SearchResponse) normalize() {
+	if r.Results == nil {
+		r.Results = []SearchVerse{}
+	}
+	for i := range r.Results {
+		if r.Results[i].Words == nil {
+			r.Results[i].Words = []Word{}
+		}
+		if r.Results[i].Translations == nil {
+			r.Results[i].Translations = []Resource{}
+		}
+	}
+}
+
+// MatchedWords returns the words of the result matching a term of the query, in the
+// order of the verse. A word matches when a term is found in any of its texts, its
+// translation or its transliteration, ignoring case and arabic diacritics. A result sent
+// without its words falls back to its TextMadani split on whitespace, so only the arabic
+// text of the verse is matched and the words have only their text and position set.
+func (v SearchVerse) MatchedWords(query string) []Word {
+	var terms []string
+	for _, term := range strings.Fields(query) {
+		if t := searchForm(term); t != "" {
+			terms = append(terms, t)
+		}
+	}
+
+	words := v.Words
+	if len(words) == 0 {
+		words = Verse{VerseKey: v.VerseKey, TextMadani: v.TextMadani}.textWords()
+	}
+
+	matched := []Word{}
+	for _, w := range words {
+		if w.CharType != "" && w.CharType != charTypeWord {
+			continue
+		}
+		if wordMatches(w, terms) {
+			matched = append(matched, w)
+		}
+	}
+	return matched
+}
+
+func wordMatches(w Word, terms []string) bool {
+	texts := []string{
+		w.TextMadani,
+		w.TextIndopak,
+		w.TextSimple,
+		w.TextUthmani,
+		w.TextUthmaniSimple,
+		w.TextImlaei,
+		w.Translation.Text,
+		w.Transliteration.Text,
+	}
+	for _, text := range texts {
+		text = searchForm(text)
+		if text == "" {
+			continue
+		}
+		for _, term := range terms {
+			if strings.Contains(text, term) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// searchForm returns the text lower cased with its diacritics dropped, the form search
+// terms and texts are compared in.
+func searchForm(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SearchMatch is a search result with the parts of it that match the query resolved.
+type SearchMatch struct {
+	SearchVerse
+
+	// MatchedWords are the words of the verse matching the query, see
+	// SearchVerse.MatchedWords. It is empty rather than nil when nothing matched.
+	MatchedWords []Word
+	// Translation is the text of the result's first translation, empty when the result
+	// was sent without translations.
+	Translation string
+}
+
+// SearchEnriched searches through the api and resolves the matched words and translation
+// of each result. Results sent without their words or translations are matched against
+// their TextMadani and left without a translation, which callers should fall back to
+// rendering the verse's TextMadani for.
+func SearchEnriched(ctx context.Context, api QuranAPI, query SearchRequest) ([]SearchMatch, error) {
+	resp, err := api.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]SearchMatch, 0, len(resp.Results))
+	for _, v := range resp.Results {
+		m := SearchMatch{
+			SearchVerse:  v,
+			MatchedWords: v.MatchedWords(query.Query),
+		}
+		if len(v.Translations) > 0 {
+			m.Translation = v.Translations[0].Text
+		}
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
@@ -0,0 +1,207 @@
+package quranc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// searchBody is a search response with a result of every shape, one with its words and
+// translations, one without words and one without translations.
+const searchBody = `{"query": "mercy", "total_count": 3, "results": [
+	{
+		"verse_key": "1:1", "chapter_id": 1, "verse_number": 1, "text_madani": "بِسْمِ اللَّهِ الرَّحْمَٰنِ الرَّحِيمِ",
+		"words": [
+			{"position": 1, "char_type": "word", "text_madani": "بِسْمِ", "translation": {"text": "In (the) name"}},
+			{"position": 2, "char_type": "word", "text_madani": "اللَّهِ", "translation": {"text": "(of) Allah"}},
+			{"position": 3, "char_type": "word", "text_madani": "الرَّحْمَٰنِ", "translation": {"text": "the Most Gracious"}},
+			{"position": 4, "char_type": "word", "text_madani": "الرَّحِيمِ", "translation": {"text": "the Most Merciful"}},
+			{"position": 5, "char_type": "end", "text_madani": "١"}
+		],
+		"translations": [{"resource_id": 20, "text": "In the name of Allah, the Entirely Merciful, the Especially Merciful."}]
+	},
+	{
+		"verse_key": "1:3", "chapter_id": 1, "verse_number": 3, "text_madani": "الرَّحْمَٰنِ الرَّحِيمِ",
+		"translations": [{"resource_id": 20, "text": "The Entirely Merciful, the Especially Merciful,"}]
+	},
+	{
+		"verse_key": "6:12", "chapter_id": 6, "verse_number": 12, "text_madani": "كَتَبَ عَلَىٰ نَفْسِهِ الرَّحْمَةَ",
+		"words": [{"position": 4, "char_type": "word", "text_madani": "الرَّحْمَةَ", "translation": {"text": "the Mercy"}}]
+	}
+]}`
+
+func TestClient_Search_missingWordsAndTranslations(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		api  func(t *testing.T) QuranAPI
+	}{
+		{name: "client", api: func(t *testing.T) QuranAPI { return newTestClient(t, searchBody) }},
+		{name: "bolt cache", api: func(t *testing.T) QuranAPI { return newTestBoltCache(t, newTestClient(t, searchBody)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := tt.api(t)
+
+			// the second search is served from the cache of a cached api.
+			for i := 0; i < 2; i++ {
+				resp, err := api.Search(ctx, SearchRequest{Query: "mercy"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(resp.Results) != 3 {
+					t.Fatalf("got %d results, want 3", len(resp.Results))
+				}
+				for _, r := range resp.Results {
+					if r.Words == nil || r.Translations == nil {
+						t.Errorf("got %s with words %#v and translations %#v, want non-nil slices", r.VerseKey, r.Words, r.Translations)
+					}
+				}
+				if n := len(resp.Results[1].Words); n != 0 {
+					t.Errorf("got %d words for a result without them", n)
+				}
+				if n := len(resp.Results[2].Translations); n != 0 {
+					t.Errorf("got %d translations for a result without them", n)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchVerse_MatchedWords(t *testing.T) {
+	verse := SearchVerse{
+		VerseKey:   "1:1",
+		TextMadani: "بِسْمِ اللَّهِ الرَّحْمَٰنِ الرَّحِيمِ",
+		Words: []Word{
+			{Position: 1, CharType: "word", TextMadani: "بِسْمِ", Translation: Resource{Text: "In (the) name"}},
+			{Position: 2, CharType: "word", TextMadani: "اللَّهِ", Translation: Resource{Text: "(of) Allah"}},
+			{Position: 3, CharType: "word", TextMadani: "الرَّحْمَٰنِ", Translation: Resource{Text: "the Most Gracious"}},
+			{Position: 4, CharType: "word", TextMadani: "الرَّحِيمِ", Translation: Resource{Text: "the Most Merciful"}, Transliteration: Resource{Text: "al-raḥīmi"}},
+			{Position: 5, CharType: "end", TextMadani: "١"},
+		},
+	}
+	withoutWords := verse
+	withoutWords.Words = nil
+
+	tests := []struct {
+		name  string
+		verse SearchVerse
+		query string
+		want  []int
+	}{
+		{name: "translation ignoring case", verse: verse, query: "MERCIFUL", want: []int{4}},
+		{name: "any term", verse: verse, query: "name gracious", want: []int{1, 3}},
+		{name: "arabic without diacritics", verse: verse, query: "الرحيم", want: []int{4}},
+		{name: "transliteration", verse: verse, query: "raḥīmi", want: []int{4}},
+		{name: "end marker is not matched", verse: verse, query: "١", want: []int{}},
+		{name: "no match", verse: verse, query: "light", want: []int{}},
+		{name: "empty query", verse: verse, query: " ", want: []int{}},
+		{name: "without words falls back to the madani text", verse: withoutWords, query: "الرحمن الرحيم", want: []int{3, 4}},
+		{name: "without words matches no translation", verse: withoutWords, query: "merciful", want: []int{}},
+		{name: "without words or text", verse: SearchVerse{VerseKey: "1:1"}, query: "merciful", want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words := tt.verse.MatchedWords(tt.query)
+			if words == nil {
+				t.Fatal("got nil, want an empty non-nil slice")
+			}
+			got := make([]int, 0, len(words))
+			for _, w := range words {
+				got = append(got, w.Position)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got positions %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchEnriched(t *testing.T) {
+	c := newTestClient(t, searchBody)
+
+	matches, err := SearchEnriched(context.Background(), c, SearchRequest{Query: "mercy merciful"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("got %d matches, want 3", len(matches))
+	}
+
+	tests := []struct {
+		key             string
+		wantWords       []int
+		wantTranslation string
+	}{
+		{key: "1:1", wantWords: []int{4}, wantTranslation: "In the name of Allah, the Entirely Merciful, the Especially Merciful."},
+		// matched against the arabic text, which an english query never matches.
+		{key: "1:3", wantWords: []int{}, wantTranslation: "The Entirely Merciful, the Especially Merciful,"},
+		{key: "6:12", wantWords: []int{4}},
+	}
+	for i, tt := range tests {
+		m := matches[i]
+		if m.VerseKey != tt.key {
+			t.Fatalf("got match %d for %s, want %s", i, m.VerseKey, tt.key)
+		}
+		got := []int{}
+		for _, w := range m.MatchedWords {
+			got = append(got, w.Position)
+		}
+		if !reflect.DeepEqual(got, tt.wantWords) {
+			t.Errorf("%s: got matched words %v, want %v", tt.key, got, tt.wantWords)
+		}
+		if m.Translation != tt.wantTranslation {
+			t.Errorf("%s: got translation %q, want %q", tt.key, m.Translation, tt.wantTranslation)
+		}
+	}
+
+	t.Run("errors are returned", func(t *testing.T) {
+		if _, err := SearchEnriched(context.Background(), c, SearchRequest{}); err == nil {
+			t.Error("got no error for an empty query")
+		}
+	})
+}
+
+func TestWithSearchDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []ClientOptFn
+		req      SearchRequest
+		wantSize string
+		wantPage string
+	}{
+		{name: "no defaults", req: SearchRequest{Query: "mercy"}},
+		{name: "defaults", opts: []ClientOptFn{WithSearchDefaults(50, 2)}, req: SearchRequest{Query: "mercy"}, wantSize: "50", wantPage: "2"},
+		{name: "request over defaults", opts: []ClientOptFn{WithSearchDefaults(50, 2)}, req: SearchRequest{Query: "mercy", Size: 5, Page: 3}, wantSize: "5", wantPage: "3"},
+		{name: "request size only", opts: []ClientOptFn{WithSearchDefaults(50, 2)}, req: SearchRequest{Query: "mercy", Size: 5}, wantSize: "5", wantPage: "2"},
+		{name: "request without defaults", req: SearchRequest{Query: "mercy", Size: 5, Page: 3}, wantSize: "5", wantPage: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query url.Values
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				w.Write([]byte(`{"results": []}`))
+			})
+			c := newHandlerClient(t, h, tt.opts...)
+
+			if _, err := c.Search(context.Background(), tt.req); err != nil {
+				t.Fatal(err)
+			}
+			for param, want := range map[string]string{"size": tt.wantSize, "page": tt.wantPage} {
+				if got := query.Get(param); got != want || query.Has(param) != (want != "") {
+					t.Errorf("got %s=%q, want %q", param, got, want)
+				}
+			}
+			if got := query.Get("q"); got != tt.req.Query {
+				t.Errorf("got q=%q, want %q", got, tt.req.Query)
+			}
+		})
+	}
+}
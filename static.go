@@ -0,0 +1,31 @@
+package quranc
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sync"
+)
+
+// staticChaptersJSON is the core metadata of the 114 chapters, which never changes, in the
+// shape of the api's chapters.
+//
+//go:embed data/chapters.json
+var staticChaptersJSON []byte
+
+var (
+	staticChaptersOnce sync.Once
+	staticChapters     []Chapter
+)
+
+// StaticChapters returns the chapters from metadata embedded in the package, without any
+// request to quran.com. It holds the chapter numbers, names, verse counts, pages, and
+// revelation place and order, so a chapter list can be shown instantly and offline. The
+// translated names are not embedded, the live Chapters is still needed for those.
+func StaticChapters() []Chapter {
+	staticChaptersOnce.Do(func() {
+		if err := json.Unmarshal(staticChaptersJSON, &staticChapters); err != nil {
+			panic("quranc: decode embedded chapters: " + err.Error())
+		}
+	})
+	return append([]Chapter(nil), staticChapters...)
+}
@@ -0,0 +1,46 @@
+package quranc
+
+import "testing"
+
+func TestStaticChapters(t *testing.T) {
+	chapters := StaticChapters()
+	if len(chapters) != ChapterCount {
+		t.Fatalf("got %d chapters, want %d", len(chapters), ChapterCount)
+	}
+
+	var verses int
+	places := make(map[RevelationPlace]int)
+	for i, ch := range chapters {
+		if ch.ID != i+1 || ch.ChapterNumber != ch.ID {
+			t.Errorf("got chapter %d numbered %d at %d", ch.ID, ch.ChapterNumber, i)
+		}
+		if ch.NameSimple == "" || ch.NameArabic == "" || ch.VersesCount < 1 {
+			t.Errorf("got chapter %d without its names or verses: %+v", ch.ID, ch)
+		}
+		if ch.Pages.Start < 1 || ch.Pages.End < ch.Pages.Start || ch.Pages.End > MushafPages {
+			t.Errorf("got chapter %d on pages %+v", ch.ID, ch.Pages)
+		}
+		noBismillah := ch.ID == 1 || ch.ID == ChapterAtTawbah
+		if ch.BismillahPre == noBismillah {
+			t.Errorf("got chapter %d with bismillah_pre %t", ch.ID, ch.BismillahPre)
+		}
+		verses += ch.VersesCount
+		places[ch.Place()]++
+	}
+	if verses != totalVerses {
+		t.Errorf("got %d verses, want %d", verses, totalVerses)
+	}
+	if places[RevelationPlaceMakkah]+places[RevelationPlaceMadinah] != ChapterCount {
+		t.Errorf("got revelation places %v", places)
+	}
+	if ch := chapters[1]; ch.NameSimple != "Al-Baqarah" || ch.VersesCount != 286 {
+		t.Errorf("got chapter 2 %+v", ch)
+	}
+
+	t.Run("callers get a copy", func(t *testing.T) {
+		StaticChapters()[0].VersesCount = 0
+		if got := StaticChapters()[0].VersesCount; got != 7 {
+			t.Errorf("got %d verses for chapter 1 after a caller changed it, want 7", got)
+		}
+	})
+}
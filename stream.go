@@ -0,0 +1,65 @@
+package quranc
+
+import "context"
+
+// StreamOpts sets how verses are streamed.
+type StreamOpts struct {
+	// Buffer is the number of verses that may be fetched ahead of the consumer. The
+	// default of zero makes the producer wait on the consumer for every verse, so a
+	// slow consumer is never overwhelmed. A negative buffer is treated as zero.
+	Buffer int
+}
+
+// VersesStream streams the verses of the chapter, fetching them a page at a time as the
+// consumer reads them. Both channels are closed once all verses are sent, an error occurs,
+// or the context is cancelled. At most one error is sent on the error channel.
+func (c *Client) VersesStream(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) (<-chan Verse, <-chan error) {
+	return c.VersesStreamOpts(ctx, chapterID, StreamOpts{}, reqOpts...)
+}
+
+// VersesStreamOpts streams the verses of the chapter as VersesStream does, with the
+// stream options provided.
+func (c *Client) VersesStreamOpts(ctx context.Context, chapterID int, streamOpts StreamOpts, reqOpts ...VersesReqOptFn) (<-chan Verse, <-chan error) {
+	var opts versesReqOpt
+	for _, optFn := range reqOpts {
+		opts = optFn(opts)
+	}
+
+	if streamOpts.Buffer < 0 {
+		streamOpts.Buffer = 0
+	}
+	verses := make(chan Verse, streamOpts.Buffer)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(verses)
+
+		page := opts.Page
+		if page < 1 {
+			page = 1
+		}
+		for {
+			pageOpts := append(reqOpts[:len(reqOpts):len(reqOpts)], VersesPage(page))
+			pageVerses, meta, err := c.versesPage(ctx, chapterID, pageOpts...)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			for _, v := range pageVerses {
+				select {
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				case verses <- v:
+				}
+			}
+
+			if len(pageVerses) == 0 || meta.lastPage(page) {
+				return
+			}
+			page = meta.NextPage
+		}
+	}()
+	return verses, errs
+}
@@ -0,0 +1,88 @@
+package quranc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_VersesStreamOpts(t *testing.T) {
+	t.Run("negative buffer is treated as zero", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"verses": []Verse{
+					{ChapterID: 1, VerseNumber: 1},
+					{ChapterID: 1, VerseNumber: 2},
+				},
+			})
+		}))
+		defer srv.Close()
+
+		c := New(WithHost(srv.URL))
+		verses, errs := c.VersesStreamOpts(context.Background(), 1, StreamOpts{Buffer: -1})
+
+		var got int
+		for range verses {
+			got++
+		}
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+		if got != 2 {
+			t.Errorf("got %d verses, want 2", got)
+		}
+	})
+
+	t.Run("slow consumer blocks the producer until cancelled", func(t *testing.T) {
+		stub := &versesStub{chapters: StaticChapters()[:2]}
+		c := newHandlerClient(t, stub)
+		const path = "/api/v3/chapters/2/verses"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		verses, errs := c.VersesStreamOpts(ctx, 2, StreamOpts{Buffer: 0}, VersesLimit(5))
+
+		<-verses
+		// give the producer the time to run ahead, were it not blocked on the consumer.
+		time.Sleep(50 * time.Millisecond)
+		if got := stub.requestCount(path); got != 1 {
+			t.Fatalf("got %d page requests before the consumer read the first page, want 1", got)
+		}
+
+		for i := 0; i < 5; i++ {
+			<-verses
+		}
+		time.Sleep(50 * time.Millisecond)
+		if got := stub.requestCount(path); got != 2 {
+			t.Fatalf("got %d page requests once the consumer read into the second page, want 2", got)
+		}
+
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for range verses {
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("the verses channel was not closed after cancelling")
+		}
+
+		// the error channel is closed by the producer as it exits, after its error.
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Errorf("got error %v, want context.Canceled", err)
+		}
+		if _, ok := <-errs; ok {
+			t.Error("the error channel was not closed")
+		}
+		if got := stub.requestCount(path); got > 3 {
+			t.Errorf("got %d page requests after cancelling, want at most 3", got)
+		}
+	})
+}
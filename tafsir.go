@@ -0,0 +1,111 @@
+package quranc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TafsirByResource returns the tafsir of every verse of the chapter from a single tafsir
+// resource, sorted by verse id. The chapter's tafsir is fetched a page at a time from the
+// v4 by chapter route, a handful of requests in place of one per verse. v3 has no such
+// route, so the method requires a client set to v4 with WithAPIVersion and returns
+// ErrUnsupportedAPIVersion on any other.
+func (c *Client) TafsirByResource(ctx context.Context, resourceID, chapterID int) ([]VerseTafsir, error) {
+	if err := validateChapter(chapterID); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := c.route("TafsirByResource", "", joinPath("tafsirs", strconv.Itoa(resourceID), "by_chapter", strconv.Itoa(chapterID)))
+	if err != nil {
+		return nil, err
+	}
+
+	tafsiraat := []VerseTafsir{}
+	for page := 1; ; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		req := c.c.Get(endpoint).
+			QueryParam("page", strconv.Itoa(page)).
+			QueryParam("per_page", strconv.Itoa(maxVersesLimit))
+
+		var resp struct {
+			Tafsirs    []VerseTafsir    `json:"tafsirs"`
+			Pagination versesPagination `json:"pagination"`
+		}
+		if err := c.do(ctx, "TafsirByResource", req, &resp); err != nil {
+			return nil, err
+		}
+
+		tafsiraat = append(tafsiraat, resp.Tafsirs...)
+		meta := resp.Pagination.meta()
+		if len(resp.Tafsirs) == 0 || meta.lastPage(page) {
+			break
+		}
+		page = meta.NextPage
+	}
+
+	sort.SliceStable(tafsiraat, func(i, j int) bool {
+		return tafsiraat[i].VerseID < tafsiraat[j].VerseID
+	})
+	return tafsiraat, nil
+}
+
+// VerseTafsirOptions returns the tafsiraat that have text for the given verse. The tafsiraat
+// available are cross referenced against the tafsir returned for the verse by the resource
+// name, as the api has no dedicated route for it. When the api is cached both lookups are
+// served from the cache.
+func VerseTafsirOptions(ctx context.Context, api QuranAPI, chapterID, verseID int) ([]Tafsir, error) {
+	tafsiraat, err := api.Tafsiraat(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verseTafsiraat, err := api.VerseTafsir(ctx, chapterID, verseID)
+	if err != nil {
+		return nil, err
+	}
+
+	withText := make(map[string]bool)
+	for _, vt := range verseTafsiraat {
+		if strings.TrimSpace(vt.Text) == "" {
+			continue
+		}
+		withText[normalizeResourceName(vt.ResourceName)] = true
+	}
+
+	out := make([]Tafsir, 0, len(tafsiraat))
+	for _, t := range tafsiraat {
+		if withText[normalizeResourceName(t.Name)] {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// WarmChapterTafsir fetches the tafsir of every verse of the chapter from the given tafsir
+// through the api, with at most concurrency requests in flight. When the api is a bolt cache
+// this populates the cache for offline study. A concurrency of zero uses the client's default,
+// set with WithDefaultConcurrency. The failures of individual verses do not stop the others,
+// they are returned together as a MultiError. Once the context is done the verses in flight
+// stop, what was cached so far stays cached, and the context's error is returned alone.
+// Progress is reported per verse to the progress function, which may be nil.
+func WarmChapterTafsir(ctx context.Context, api QuranAPI, chapterID, tafsirID, concurrency int, progress ProgressFunc) error {
+	chapter, err := api.Chapter(ctx, chapterID)
+	if err != nil {
+		return err
+	}
+
+	_, err = batch(ctx, chapter.VersesCount, apiConcurrency(api, concurrency), progress, nil, func(ctx context.Context, i int) error {
+		verseID := i + 1
+		if _, err := api.VerseTafsir(ctx, chapterID, verseID, TafsirID(tafsirID)); err != nil {
+			return fmt.Errorf("verse %s tafsir: %w", verseKey(chapterID, verseID), err)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,187 @@
+package quranc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestVerseTafsirOptions(t *testing.T) {
+	t.Run("no tafsiraat is empty", func(t *testing.T) {
+		got, err := VerseTafsirOptions(context.Background(), emptyAPI{}, 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("got %#v, want an empty non-nil slice", got)
+		}
+	})
+}
+
+func TestWarmChapterTafsir(t *testing.T) {
+	t.Run("populates the verse tafsir cache", func(t *testing.T) {
+		ctx := context.Background()
+		next := new(countingAPI)
+		db := newTestDB(t)
+		api, err := BoltCache(next, db)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// chapter 108 is 3 verses long.
+		if err := WarmChapterTafsir(ctx, api, 108, 169, 2, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := bucketLen(t, db, bucketVerses, bucketVerseTafsir); got != 3 {
+			t.Fatalf("got %d cached verse tafsir, want 3", got)
+		}
+		if calls := next.callCount("VerseTafsir"); calls != 3 {
+			t.Fatalf("got %d upstream calls, want 3", calls)
+		}
+
+		for verse := 1; verse <= 3; verse++ {
+			if _, err := api.VerseTafsir(ctx, 108, verse, TafsirID(169)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if calls := next.callCount("VerseTafsir"); calls != 3 {
+			t.Errorf("got %d upstream calls after the warm, want the 3 of the warm", calls)
+		}
+	})
+
+	t.Run("cancelled warm is context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := WarmChapterTafsir(ctx, new(countingAPI), 2, 169, 2, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestClient_TafsirByResource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pages are merged and sorted by verse id", func(t *testing.T) {
+		// verse ids are numbered across the mushaf, al-baqarah starts at 8.
+		pages := map[string]string{
+			"1": `{"tafsirs": [
+				{"id": 3, "verse_id": 10, "verse_key": "2:3", "text": "third"},
+				{"id": 1, "verse_id": 8, "verse_key": "2:1", "text": "first"}
+			], "pagination": {"per_page": 2, "current_page": 1, "next_page": 2, "total_pages": 2, "total_records": 4}}`,
+			"2": `{"tafsirs": [
+				{"id": 4, "verse_id": 11, "verse_key": "2:4", "text": "fourth"},
+				{"id": 2, "verse_id": 9, "verse_key": "2:2", "text": "second"}
+			], "pagination": {"per_page": 2, "current_page": 2, "next_page": null, "total_pages": 2, "total_records": 4}}`,
+		}
+		var requests int32
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if r.URL.Path != "/api/v4/tafsirs/169/by_chapter/2" {
+				t.Errorf("got request to %s", r.URL.Path)
+			}
+			body, ok := pages[r.URL.Query().Get("page")]
+			if !ok {
+				t.Errorf("got request for page %q", r.URL.Query().Get("page"))
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(body))
+		})
+		c := newHandlerClient(t, h, WithAPIVersion("v4"))
+
+		got, err := c.TafsirByResource(ctx, 169, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := atomic.LoadInt32(&requests); n != 2 {
+			t.Errorf("sent %d requests, want 2", n)
+		}
+		if len(got) != 4 {
+			t.Fatalf("got %d tafsiraat, want 4", len(got))
+		}
+		for i, tafsir := range got {
+			if want := 8 + i; tafsir.VerseID != want {
+				t.Errorf("got verse id %d at %d, want %d", tafsir.VerseID, i, want)
+			}
+			key, err := ParseVerseKey(tafsir.VerseKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (VerseKey{Chapter: 2, Verse: i + 1}); key != want {
+				t.Errorf("got verse key %v at %d, want %v", key, i, want)
+			}
+		}
+	})
+
+	t.Run("no tafsir is empty", func(t *testing.T) {
+		c := newTestClient(t, `{"tafsirs": [], "pagination": {}}`, WithAPIVersion("v4"))
+
+		got, err := c.TafsirByResource(ctx, 169, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Errorf("got %#v, want an empty non-nil slice", got)
+		}
+	})
+
+	t.Run("v3 is unsupported", func(t *testing.T) {
+		var requests int32
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+		})
+		c := newHandlerClient(t, h)
+
+		if _, err := c.TafsirByResource(ctx, 169, 1); !errors.Is(err, ErrUnsupportedAPIVersion) {
+			t.Errorf("got error %v, want ErrUnsupportedAPIVersion", err)
+		}
+		if n := atomic.LoadInt32(&requests); n != 0 {
+			t.Errorf("sent %d requests, want none", n)
+		}
+	})
+}
+
+func TestVerseTafsir_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "string", key: `"2:255"`, want: "2:255"},
+		{name: "object", key: `{"chapter": 2, "verse": 255}`, want: "2:255"},
+		{name: "object of ids", key: `{"chapter_id": 2, "verse_number": 255}`, want: "2:255"},
+		{name: "object without a verse", key: `{"chapter": 2}`},
+		{name: "number", key: `262`, want: "262"},
+		{name: "null", key: `null`},
+		{name: "boolean", key: `true`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got VerseTafsir
+			body := `{"id": 1, "text": "tafsir", "verse_id": 262, "verse_key": ` + tt.key + `}`
+			if err := json.Unmarshal([]byte(body), &got); err != nil {
+				t.Fatal(err)
+			}
+			want := VerseTafsir{ID: 1, Text: "tafsir", VerseID: 262, VerseKey: tt.want}
+			if got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		var got VerseTafsir
+		if err := json.Unmarshal([]byte(`{"id": 1}`), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.VerseKey != "" {
+			t.Errorf("got verse key %q, want it empty", got.VerseKey)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package quranc
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the client's spans.
+const tracerName = "github.com/alilmtech/quranc"
+
+// startSpan starts the span of a call to the api method, nested under the span of the
+// context. The returned function ends the span, recording the call's error on it. Without
+// a tracer no span is started.
+func (c *Client) startSpan(ctx context.Context, method string) (context.Context, func(error)) {
+	if c.tracer == nil {
+		return ctx, func(error) {}
+	}
+
+	ctx, span := c.tracer.Start(ctx, "quranc."+method, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// spanAttributesDoer sets the chapter id, page and language of every request on the span
+// of its context. They are read from the request's url, the path carries the chapter id
+// and the page of the by page routes, the query the rest.
+func spanAttributesDoer(next Doer) Doer {
+	return doerFunc(func(r *http.Request) (*http.Response, error) {
+		span := trace.SpanFromContext(r.Context())
+		if !span.IsRecording() {
+			return next.Do(r)
+		}
+
+		var attrs []attribute.KeyValue
+		segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		for i := 0; i+1 < len(segments); i++ {
+			id, err := strconv.Atoi(segments[i+1])
+			if err != nil {
+				continue
+			}
+			switch segments[i] {
+			case "chapters", "by_chapter":
+				attrs = append(attrs, attribute.Int("quranc.chapter_id", id))
+			case "by_page":
+				attrs = append(attrs, attribute.Int("quranc.page", id))
+			}
+		}
+
+		query := r.URL.Query()
+		if page, err := strconv.Atoi(query.Get("page")); err == nil {
+			attrs = append(attrs, attribute.Int("quranc.page", page))
+		}
+		if lang := query.Get("language"); lang != "" {
+			attrs = append(attrs, attribute.String("quranc.language", lang))
+		}
+		span.SetAttributes(attrs...)
+
+		return next.Do(r)
+	})
+}
@@ -0,0 +1,610 @@
+package quranc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// VerseInChapter returns the verse of the chapter after validating the verse number against
+// the chapter's verse count. The chapter is looked up through the api provided, so when the
+// api is cached, a verse number out of range, i.e. verse 300 of a 7 verse chapter, returns
+// ErrNotFound without a trip to quran.com.
+func VerseInChapter(ctx context.Context, api QuranAPI, chapterID, verseNumber int) (Verse, error) {
+	chapter, err := api.Chapter(ctx, chapterID)
+	if err != nil {
+		return Verse{}, err
+	}
+
+	if verseNumber < 1 || verseNumber > chapter.VersesCount {
+		return Verse{}, fmt.Errorf("verse %d:%d: %w", chapterID, verseNumber, ErrNotFound)
+	}
+
+	return api.Verse(ctx, chapterID, verseNumber)
+}
+
+// VerseByKey returns the verse with the key provided, i.e. "2:255". A malformed key, or one
+// whose chapter does not exist, returns an error without a trip to quran.com. The options
+// are passed on to the api's Verse.
+func VerseByKey(ctx context.Context, api QuranAPI, key string, reqOpts ...VersesReqOptFn) (Verse, error) {
+	chapterID, verseNumber, err := parseVerseKey(key)
+	if err != nil {
+		return Verse{}, err
+	}
+	return api.Verse(ctx, chapterID, verseNumber, reqOpts...)
+}
+
+// VersesBatch fetches the verses with the keys provided through the api, with at most
+// concurrency requests in flight, returning them keyed by verse key, i.e. "2:255". A
+// concurrency of zero uses the api's default. The keys that fail are left out of the map
+// and their errors returned, without stopping the others. Once the context is done no
+// new requests are started and the context's error is returned in place of the others.
+func VersesBatch(ctx context.Context, api QuranAPI, keys []VerseKey, concurrency int, reqOpts ...VersesReqOptFn) (map[string]Verse, []error) {
+	var (
+		mu     sync.Mutex
+		verses = make(map[string]Verse, len(keys))
+	)
+	_, err := batch(ctx, len(keys), apiConcurrency(api, concurrency), nil, nil, func(ctx context.Context, i int) error {
+		key := keys[i]
+		if _, err := ParseVerseKey(key.String()); err != nil {
+			return err
+		}
+		v, err := api.Verse(ctx, key.Chapter, key.Verse, reqOpts...)
+		if err != nil {
+			return fmt.Errorf("verse %s: %w", key, err)
+		}
+
+		mu.Lock()
+		verses[key.String()] = v
+		mu.Unlock()
+		return nil
+	})
+
+	if errs, ok := err.(MultiError); ok {
+		return verses, errs
+	}
+	if err != nil {
+		return verses, []error{err}
+	}
+	return verses, nil
+}
+
+// Char types of a verse's words. A word is part of the verse's text, the end is the glyph
+// marking the end of the verse.
+const (
+	charTypeWord = "word"
+	charTypeEnd  = "end"
+)
+
+// WordsOnly returns the words of the verse that are part of its text, leaving out glyphs
+// like the end of verse marker. A verse fetched without its words falls back to its
+// verse-level text split on whitespace, with only the text and position of each word set.
+func (v Verse) WordsOnly() []Word {
+	if len(v.Words) == 0 {
+		return v.textWords()
+	}
+
+	words := make([]Word, 0, len(v.Words))
+	for _, w := range v.Words {
+		if w.CharType == charTypeWord {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// EndMarker returns the end of verse marker of the verse's words. A verse fetched without
+// its words falls back to a marker of the verse number in arabic-indic digits, the glyph
+// the api sends. False is returned when the verse has neither.
+func (v Verse) EndMarker() (Word, bool) {
+	if len(v.Words) == 0 {
+		if v.VerseNumber < 1 {
+			return Word{}, false
+		}
+		marker := Word{
+			Position: len(v.textWords()) + 1,
+			VerseKey: v.VerseKey,
+			CharType: charTypeEnd,
+		}
+		for _, t := range wordTextTypes {
+			marker.setText(t, arabicIndicDigits(v.VerseNumber))
+		}
+		return marker, true
+	}
+
+	for _, w := range v.Words {
+		if w.CharType == charTypeEnd {
+			return w, true
+		}
+	}
+	return Word{}, false
+}
+
+// wordTextTypes are the text types a word's text is available in.
+var wordTextTypes = []string{
+	TextTypeMadani,
+	TextTypeIndopak,
+	TextTypeSimple,
+	TextTypeUthmani,
+	TextTypeUthmaniSimple,
+	TextTypeImlaei,
+}
+
+// textWords splits the verse-level text of each text type into words. The words are as
+// many as the first text type the verse has text in splits into, a text type that splits
+// into a different number of words is left out, its word boundaries do not line up.
+func (v Verse) textWords() []Word {
+	var words []Word
+	for _, t := range wordTextTypes {
+		fields := strings.Fields(v.text(t))
+		if len(fields) == 0 {
+			continue
+		}
+		if words == nil {
+			words = make([]Word, len(fields))
+			for i := range words {
+				words[i] = Word{Position: i + 1, VerseKey: v.VerseKey, CharType: charTypeWord}
+			}
+		}
+		if len(fields) != len(words) {
+			continue
+		}
+		for i, f := range fields {
+			words[i].setText(t, f)
+		}
+	}
+	if words == nil {
+		return []Word{}
+	}
+	return words
+}
+
+func (w *Word) setText(textType, text string) {
+	switch textType {
+	case TextTypeMadani:
+		w.TextMadani = text
+	case TextTypeIndopak:
+		w.TextIndopak = text
+	case TextTypeSimple:
+		w.TextSimple = text
+	case TextTypeUthmani:
+		w.TextUthmani = text
+	case TextTypeUthmaniSimple:
+		w.TextUthmaniSimple = text
+	case TextTypeImlaei:
+		w.TextImlaei = text
+	}
+}
+
+// arabicIndicDigits formats the number in arabic-indic digits, i.e. 255 as "٢٥٥".
+func arabicIndicDigits(n int) string {
+	digits := []rune(strconv.Itoa(n))
+	for i, d := range digits {
+		digits[i] = '٠' + d - '0'
+	}
+	return string(digits)
+}
+
+// ChapterWordCount returns the number of words in the chapter, excluding glyphs like the
+// end of verse markers. It requires fetching all the verses of the chapter with their
+// words, so callers should prefer a cached client.
+func (c *Client) ChapterWordCount(ctx context.Context, chapterID int) (int, error) {
+	if err := validateChapter(chapterID); err != nil {
+		return 0, err
+	}
+
+	chapter, err := c.Chapter(ctx, chapterID)
+	if err != nil {
+		return 0, err
+	}
+
+	verses, err := chapterVerses(ctx, c, chapterID, chapter.VersesCount, VersesIncludeWords(true))
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, v := range verses {
+		count += len(v.WordsOnly())
+	}
+	return count, nil
+}
+
+// VersesAll returns all the verses of the chapter, fetched a page at a time. A Client
+// follows the next page quran.com reports with each page, other apis are paged until a
+// short page. The context is checked between pages. The pages are merged into a single
+// list ordered by chapter and verse number, with any verse returned on more than one page
+// kept once, so offset and limit combinations that overlap pages still produce a correct
+// result. The ordering may be skipped with VersesKeepArrivalOrder.
+func VersesAll(ctx context.Context, api QuranAPI, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	if err := validateChapter(chapterID); err != nil {
+		return nil, err
+	}
+
+	var opts versesReqOpt
+	for _, optFn := range reqOpts {
+		opts = optFn(opts)
+	}
+
+	var verses []Verse
+	if pager, ok := api.(versesPager); ok {
+		var err error
+		if verses, err = pagedVerses(ctx, pager, chapterID, reqOpts...); err != nil {
+			return nil, err
+		}
+	} else {
+		chapter, err := api.Chapter(ctx, chapterID)
+		if err != nil {
+			return nil, err
+		}
+		if verses, err = chapterVerses(ctx, api, chapterID, chapter.VersesCount, reqOpts...); err != nil {
+			return nil, err
+		}
+	}
+	if opts.arrivalOrder {
+		return verses, nil
+	}
+
+	sortVersesByKey(verses)
+
+	var (
+		seenIDs  = make(map[int]bool, len(verses))
+		seenKeys = make(map[string]bool)
+		deduped  = verses[:0]
+	)
+	for _, v := range verses {
+		// verses decoded without an id fall back to their key
+		if v.ID == 0 {
+			if seenKeys[v.VerseKey] {
+				continue
+			}
+			seenKeys[v.VerseKey] = true
+		} else {
+			if seenIDs[v.ID] {
+				continue
+			}
+			seenIDs[v.ID] = true
+		}
+		deduped = append(deduped, v)
+	}
+	return deduped, nil
+}
+
+// versesPager is implemented by a QuranAPI that returns the pagination meta of a page of
+// verses, letting VersesAll follow the next page the api reports instead of guessing the
+// last page from a short one.
+type versesPager interface {
+	versesPage(ctx context.Context, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, versesMeta, error)
+}
+
+// pagedVerses fetches every page of the chapter's verses, following the next page of each
+// page's meta until the last page.
+func pagedVerses(ctx context.Context, pager versesPager, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	verses := []Verse{}
+	for page := 1; ; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		opts := append(reqOpts[:len(reqOpts):len(reqOpts)], VersesLimit(maxVersesLimit), VersesPage(page))
+		pageVerses, meta, err := pager.versesPage(ctx, chapterID, opts...)
+		if err != nil {
+			return nil, err
+		}
+		verses = append(verses, pageVerses...)
+		if len(pageVerses) == 0 || meta.lastPage(page) {
+			return verses, nil
+		}
+		page = meta.NextPage
+	}
+}
+
+// VersesMap returns the verses of the chapter keyed by their verse key, i.e. "2:255", for
+// random access. The verses are fetched with Verses, so only the page of verses the options
+// select is returned, and the order of the verses is lost.
+func VersesMap(ctx context.Context, api QuranAPI, chapterID int, reqOpts ...VersesReqOptFn) (map[string]Verse, error) {
+	verses, err := api.Verses(ctx, chapterID, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]Verse, len(verses))
+	for _, v := range verses {
+		m[v.VerseKey] = v
+	}
+	return m, nil
+}
+
+// VersesWithChapterName returns the verses of the chapter along with the chapter's simple
+// name, i.e. "Al-Fatihah", for rendering verses under their chapter's name. The chapter is
+// looked up through the api, so when it is cached the name costs no extra request.
+func VersesWithChapterName(ctx context.Context, api QuranAPI, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, string, error) {
+	chapter, err := api.Chapter(ctx, chapterID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	verses, err := api.Verses(ctx, chapterID, reqOpts...)
+	if err != nil {
+		return nil, "", err
+	}
+	return verses, chapter.NameSimple, nil
+}
+
+// sortVersesByKey sorts the verses in the order of their keys, by chapter then verse
+// number, keeping the order of verses with the same key.
+func sortVersesByKey(verses []Verse) {
+	sort.SliceStable(verses, func(i, j int) bool {
+		if verses[i].ChapterID != verses[j].ChapterID {
+			return verses[i].ChapterID < verses[j].ChapterID
+		}
+		return verses[i].VerseNumber < verses[j].VerseNumber
+	})
+}
+
+// VersesKeepArrivalOrder makes VersesAll return the verses in the order the pages arrived
+// in, skipping the sort and dedupe. It is a performance option for callers that trust
+// the pages not to overlap, it is not sent to quran.com.
+func VersesKeepArrivalOrder() VersesReqOptFn {
+	return func(opts versesReqOpt) versesReqOpt {
+		opts.arrivalOrder = true
+		return opts
+	}
+}
+
+// defaultWordLanguage is the language word translations are requested in when none is set.
+const defaultWordLanguage = "en"
+
+// wordDetailFields are the word fields VersesWithWordDetails requests.
+var wordDetailFields = []string{"translation", "transliteration"}
+
+// VersesWithWordDetails returns the verses of the chapter with their words, and each word's
+// Translation and Transliteration populated. The words are always included along with the
+// translation and transliteration word fields, added to any set with VersesWordFields. The
+// word translations are in the language set with VersesLanguage, english when it is not set.
+func VersesWithWordDetails(ctx context.Context, api QuranAPI, chapterID int, reqOpts ...VersesReqOptFn) ([]Verse, error) {
+	var opts versesReqOpt
+	for _, optFn := range reqOpts {
+		opts = optFn(opts)
+	}
+
+	wordFields := append([]string(nil), opts.WordFields...)
+	requested := make(map[string]bool, len(wordFields))
+	for _, f := range wordFields {
+		requested[f] = true
+	}
+	for _, f := range wordDetailFields {
+		if !requested[f] {
+			wordFields = append(wordFields, f)
+		}
+	}
+
+	reqOpts = append(reqOpts[:len(reqOpts):len(reqOpts)], VersesIncludeWords(true), VersesWordFields(wordFields...))
+	if opts.Language == "" {
+		reqOpts = append(reqOpts, VersesLanguage(defaultWordLanguage))
+	}
+	return api.Verses(ctx, chapterID, reqOpts...)
+}
+
+// GroupVersesByJuz groups the verses by the juz they belong to. The verses of each juz
+// keep the order they were provided in. This is useful for annotating juz boundaries
+// within a chapter that spans more than one juz, i.e. Al-Baqarah.
+func GroupVersesByJuz(verses []Verse) map[int][]Verse {
+	groups := make(map[int][]Verse)
+	for _, v := range verses {
+		groups[v.JuzNumber] = append(groups[v.JuzNumber], v)
+	}
+	return groups
+}
+
+// SortedJuz returns the juz numbers of the groups from GroupVersesByJuz in ascending order.
+func SortedJuz(groups map[int][]Verse) []int {
+	juzzah := make([]int, 0, len(groups))
+	for juz := range groups {
+		juzzah = append(juzzah, juz)
+	}
+	sort.Ints(juzzah)
+	return juzzah
+}
+
+// PageLineCount returns the number of lines the words of the verses occupy on the given
+// mushaf page, the largest line number of any word on the page. Pages usually have 15
+// lines. Zero is returned when none of the words are on the page.
+func PageLineCount(verses []Verse, page int) int {
+	var lines int
+	for _, v := range verses {
+		for _, w := range v.Words {
+			if w.PageNumber == page && w.LineNumber > lines {
+				lines = w.LineNumber
+			}
+		}
+	}
+	return lines
+}
+
+// Text types of the scripts a verse's text is available in.
+const (
+	TextTypeMadani  = "madani"
+	TextTypeIndopak = "indopak"
+	TextTypeSimple  = "simple"
+
+	TextTypeUthmani       = "uthmani"
+	TextTypeUthmaniSimple = "uthmani_simple"
+	TextTypeImlaei        = "imlaei"
+)
+
+type (
+	// TextOptFn is an option to set the options of Verse.Text.
+	TextOptFn func(opt textOpt) textOpt
+
+	textOpt struct {
+		fallback []string
+	}
+)
+
+// WithTextTypeFallback sets the text types to fall back through, in order, when the verse's
+// text in the requested type is empty, i.e. indopak -> madani -> simple.
+func WithTextTypeFallback(order ...string) TextOptFn {
+	return func(opt textOpt) textOpt {
+		opt.fallback = order
+		return opt
+	}
+}
+
+// Text returns the verse's text in the given text type. An empty string is returned when
+// the verse has no text in the text type, or any of its fallbacks.
+func (v Verse) Text(textType string, opts ...TextOptFn) string {
+	var opt textOpt
+	for _, o := range opts {
+		opt = o(opt)
+	}
+
+	for _, t := range append([]string{textType}, opt.fallback...) {
+		if text := v.text(t); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+func (v Verse) text(textType string) string {
+	switch textType {
+	case TextTypeMadani:
+		return v.TextMadani
+	case TextTypeIndopak:
+		return v.TextIndopak
+	case TextTypeSimple:
+		return v.TextSimple
+	case TextTypeUthmani:
+		return v.TextUthmani
+	case TextTypeUthmaniSimple:
+		return v.TextUthmaniSimple
+	case TextTypeImlaei:
+		return v.TextImlaei
+	default:
+		return ""
+	}
+}
+
+// contentTextTypes are the text types a verse's content key is derived from, in order of
+// preference. They are the plain scripts, which read the same regardless of the mushaf the
+// verse was fetched with.
+var contentTextTypes = []string{TextTypeSimple, TextTypeUthmaniSimple, TextTypeImlaei, TextTypeMadani}
+
+// ContentKey returns a key identifying the verse's content, a hash of its verse key and
+// text. Fields that vary between mushafs, like the page number and the words' glyph codes,
+// are left out, so the same verse fetched with different mushafs has the same content key.
+// This is useful to dedupe verses across mushafs.
+func (v Verse) ContentKey() string {
+	h := sha256.New()
+	h.Write([]byte(v.VerseKey))
+	h.Write([]byte{0})
+	h.Write([]byte(v.Text(contentTextTypes[0], WithTextTypeFallback(contentTextTypes[1:]...))))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PageForWord returns the mushaf page the word at the given position of the verse is printed
+// on. Verses may span two pages, this supports turning the page as a recitation crosses the
+// boundary mid verse. False is returned when the verse has no word at the position, which
+// includes verses fetched without their words.
+func (v Verse) PageForWord(position int) (int, bool) {
+	for _, w := range v.Words {
+		if w.Position == position {
+			return w.PageNumber, true
+		}
+	}
+	return 0, false
+}
+
+// VerseTranslationsMulti returns the translations of the verse for each of the translation
+// ids, fetched with a single verse call, keyed by the translation's language name. When more
+// than one of the translations is in the same language, those translations are keyed by their
+// resource id instead, i.e. "20" and "131", so that none of them are lost.
+func VerseTranslationsMulti(ctx context.Context, api QuranAPI, chapterID, verseID int, translationIDs []int) (map[string]Resource, error) {
+	verse, err := api.Verse(ctx, chapterID, verseID, VersesTranslations(translationIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	perLanguage := make(map[string]int)
+	for _, r := range verse.Translations {
+		perLanguage[r.LanguageName]++
+	}
+
+	translations := make(map[string]Resource, len(verse.Translations))
+	for _, r := range verse.Translations {
+		key := r.LanguageName
+		if perLanguage[key] > 1 {
+			key = strconv.Itoa(r.ResourceID)
+		}
+		translations[key] = r
+	}
+	return translations, nil
+}
+
+// SplitChapterIntoPortions fetches all the verses of the chapter and splits them into the
+// number of portions provided, in order, as evenly as possible. When the verses do not
+// divide evenly the earlier portions have one verse more than the later ones, i.e. the 7
+// verses of Al-Fatihah split into 3 portions of 3, 2 and 2 verses.
+func SplitChapterIntoPortions(ctx context.Context, api QuranAPI, chapterID, portions int, reqOpts ...VersesReqOptFn) ([][]Verse, error) {
+	if err := validateChapter(chapterID); err != nil {
+		return nil, err
+	}
+
+	chapter, err := api.Chapter(ctx, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	if portions < 1 || portions > chapter.VersesCount {
+		return nil, fmt.Errorf("invalid portions %d: must be between 1 and %d", portions, chapter.VersesCount)
+	}
+
+	verses, err := chapterVerses(ctx, api, chapterID, chapter.VersesCount, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(verses) < portions {
+		return nil, fmt.Errorf("invalid portions %d: only %d verses returned for chapter %d", portions, len(verses), chapterID)
+	}
+
+	size, rem := len(verses)/portions, len(verses)%portions
+	split := make([][]Verse, 0, portions)
+	for i := 0; i < portions; i++ {
+		n := size
+		if i < rem {
+			n++
+		}
+		split = append(split, verses[:n:n])
+		verses = verses[n:]
+	}
+	return split, nil
+}
+
+// InternTranslations makes the verses' translations with identical text share a single
+// string, i.e. a translator's repeated basmala, to cut the memory held by large sets of
+// verses kept in memory. The resource and language names, repeated on every translation of
+// a translator, are shared the same way. The verses are updated in place.
+func InternTranslations(verses []Verse) {
+	strs := make(map[string]string)
+	intern := func(s *string) {
+		if interned, ok := strs[*s]; ok {
+			*s = interned
+			return
+		}
+		strs[*s] = *s
+	}
+
+	for i := range verses {
+		for j := range verses[i].Translations {
+			t := &verses[i].Translations[j]
+			intern(&t.Text)
+			intern(&t.ResourceName)
+			intern(&t.LanguageName)
+		}
+	}
+}
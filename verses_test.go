@@ -0,0 +1,1152 @@
+package quranc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestClient_VersesIncludeWords(t *testing.T) {
+	// reduced is a verse as the api sends it without its words.
+	const reduced = `{"verses": [{"id": 1, "verse_number": 1, "chapter_id": 112, "verse_key": "112:1", "text_madani": "قُلْ هُوَ اللَّهُ أَحَدٌ", "text_simple": "قل هو الله أحد"}], "meta": {}}`
+
+	tests := []struct {
+		name       string
+		version    string
+		reqOpts    []VersesReqOptFn
+		wantWords  string
+		wantFields bool
+	}{
+		{name: "v3 default sends no param", version: "v3"},
+		{name: "v3 included sends no param", version: "v3", reqOpts: []VersesReqOptFn{VersesIncludeWords(true)}},
+		{name: "v3 excluded", version: "v3", reqOpts: []VersesReqOptFn{VersesIncludeWords(false)}, wantWords: "false"},
+		{name: "v4 default", version: "v4", wantWords: "true", wantFields: true},
+		{name: "v4 excluded", version: "v4", reqOpts: []VersesReqOptFn{VersesIncludeWords(false)}, wantWords: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if got := q.Get("words"); got != tt.wantWords {
+					t.Errorf("got words=%q, want %q", got, tt.wantWords)
+				}
+				if got := q.Has("word_fields"); got != tt.wantFields {
+					t.Errorf("got word_fields sent %t, want %t", got, tt.wantFields)
+				}
+				w.Write([]byte(reduced))
+			})
+			c := newHandlerClient(t, h, WithAPIVersion(tt.version))
+
+			verses, err := c.Verses(context.Background(), 112, tt.reqOpts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(verses) != 1 || len(verses[0].Words) != 0 {
+				t.Fatalf("got %+v, want a single verse without words", verses)
+			}
+		})
+	}
+}
+
+func TestVerse_wordsFallback(t *testing.T) {
+	t.Run("words are taken from the words sent", func(t *testing.T) {
+		v := Verse{
+			VerseNumber: 1,
+			TextMadani:  "قُلْ هُوَ",
+			Words: []Word{
+				{Position: 1, CharType: charTypeWord, TextMadani: "قُلْ"},
+				{Position: 2, CharType: charTypeEnd, TextMadani: "end"},
+			},
+		}
+		if got := v.WordsOnly(); len(got) != 1 || got[0].TextMadani != "قُلْ" {
+			t.Errorf("got words %+v", got)
+		}
+		if got, ok := v.EndMarker(); !ok || got.TextMadani != "end" {
+			t.Errorf("got end marker %+v, %t", got, ok)
+		}
+	})
+
+	t.Run("verses without words fall back to the verse text", func(t *testing.T) {
+		v := Verse{
+			VerseNumber: 12,
+			VerseKey:    "2:12",
+			TextMadani:  "أَلَا إِنَّهُمْ",
+			TextSimple:  "ألا إنهم",
+			// a script with other word boundaries is left out.
+			TextIndopak: "أَلَآ إِنَّهُمۡ هُمُ",
+		}
+
+		want := []Word{
+			{Position: 1, VerseKey: "2:12", CharType: charTypeWord, TextMadani: "أَلَا", TextSimple: "ألا"},
+			{Position: 2, VerseKey: "2:12", CharType: charTypeWord, TextMadani: "إِنَّهُمْ", TextSimple: "إنهم"},
+		}
+		if got := v.WordsOnly(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got words %+v\nwant %+v", got, want)
+		}
+
+		marker, ok := v.EndMarker()
+		if !ok {
+			t.Fatal("got no end marker")
+		}
+		if marker.Position != 3 || marker.CharType != charTypeEnd || marker.TextUthmani != "١٢" || marker.TextMadani != "١٢" {
+			t.Errorf("got end marker %+v", marker)
+		}
+	})
+
+	t.Run("a verse without words or text", func(t *testing.T) {
+		var v Verse
+		if got := v.WordsOnly(); got == nil || len(got) != 0 {
+			t.Errorf("got %#v, want an empty non-nil slice", got)
+		}
+		if _, ok := v.EndMarker(); ok {
+			t.Error("got an end marker for a verse without a number")
+		}
+	})
+}
+
+func TestVersesWithWordDetails(t *testing.T) {
+	// v3Body and v4Body are a verse whose words carry their translation and
+	// transliteration, in the shape of each api version.
+	const v3Body = `{"verses": [{"id": 1, "verse_number": 1, "chapter_id": 1, "verse_key": "1:1", "words": [
+		{"id": 1, "position": 1, "char_type": "word", "text_madani": "بِسْمِ", "translation": {"text": "In (the) name", "language_name": "english"}, "transliteration": {"text": "bis'mi", "language_name": "english"}},
+		{"id": 2, "position": 2, "char_type": "end", "text_madani": "١"}
+	]}], "meta": {}}`
+	const v4Body = `{"verses": [{"id": 1, "verse_number": 1, "verse_key": "1:1", "words": [
+		{"id": 1, "position": 1, "char_type_name": "word", "text": "بِسْمِ", "translation": {"text": "In (the) name", "language_name": "english"}, "transliteration": {"text": "bis'mi", "language_name": "english"}},
+		{"id": 2, "position": 2, "char_type_name": "end", "text": "١"}
+	]}], "pagination": {}}`
+
+	tests := []struct {
+		name           string
+		version        string
+		body           string
+		reqOpts        []VersesReqOptFn
+		wantWordFields []string
+		wantLanguage   string
+	}{
+		{
+			name:           "v3",
+			version:        "v3",
+			body:           v3Body,
+			wantWordFields: []string{"translation", "transliteration"},
+			wantLanguage:   "en",
+		},
+		{
+			name:           "v3 adds to the word fields set",
+			version:        "v3",
+			body:           v3Body,
+			reqOpts:        []VersesReqOptFn{VersesWordFields("code_v3", "transliteration"), VersesLanguage("ur")},
+			wantWordFields: []string{"code_v3", "transliteration", "translation"},
+			wantLanguage:   "ur",
+		},
+		{
+			name:           "v4",
+			version:        "v4",
+			body:           v4Body,
+			wantWordFields: []string{"verse_key", "text_uthmani", "text_uthmani_simple", "text_imlaei", "text_indopak", "translation", "transliteration"},
+			wantLanguage:   "en",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if got := strings.Split(q.Get("word_fields"), ","); !reflect.DeepEqual(got, tt.wantWordFields) {
+					t.Errorf("got word fields %q, want %q", got, tt.wantWordFields)
+				}
+				if got := q.Get("language"); got != tt.wantLanguage {
+					t.Errorf("got language %q, want %q", got, tt.wantLanguage)
+				}
+				if q.Get("words") == "false" {
+					t.Error("words are excluded")
+				}
+				w.Write([]byte(tt.body))
+			})
+			c := newHandlerClient(t, h, WithAPIVersion(tt.version))
+
+			verses, err := VersesWithWordDetails(context.Background(), c, 1, append(tt.reqOpts, VersesIncludeWords(false))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(verses) != 1 {
+				t.Fatalf("got %d verses, want 1", len(verses))
+			}
+			words := verses[0].WordsOnly()
+			if len(words) != 1 {
+				t.Fatalf("got %d words, want 1", len(words))
+			}
+			if got := words[0]; got.Transliteration.Text != "bis'mi" || got.Translation.Text != "In (the) name" || got.Translation.LanguageName != "english" {
+				t.Errorf("got word %+v", got)
+			}
+		})
+	}
+}
+
+func TestClient_VersesIncludeAudio(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		param     string
+		reqOpts   []VersesReqOptFn
+		wantAudio bool
+	}{
+		{name: "v3 included", version: "v3", param: "recitation", reqOpts: []VersesReqOptFn{VersesRecitation(7)}, wantAudio: true},
+		{name: "v3 excluded", version: "v3", param: "recitation", reqOpts: []VersesReqOptFn{VersesRecitation(7), VersesIncludeAudio(false)}},
+		{name: "v3 no recitation", version: "v3", param: "recitation"},
+		{name: "v4 included", version: "v4", param: "audio", reqOpts: []VersesReqOptFn{VersesRecitation(7)}, wantAudio: true},
+		{name: "v4 excluded", version: "v4", param: "audio", reqOpts: []VersesReqOptFn{VersesIncludeAudio(false), VersesRecitation(7)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				for _, p := range []string{"recitation", "audio"} {
+					if q.Has(p) && (p != tt.param || !tt.wantAudio) {
+						t.Errorf("got %s=%q sent", p, q.Get(p))
+					}
+				}
+
+				// the api only attaches audio to the verses when a recitation is asked for, v3
+				// sends the segments as strings.
+				verse := map[string]interface{}{"id": 1, "verse_number": 1, "chapter_id": 1, "verse_key": "1:1"}
+				if recitation := q.Get(tt.param); recitation != "" {
+					var segments interface{} = [][]string{{"0", "1", "0", "2240"}}
+					if tt.version == "v4" {
+						segments = [][]int{{0, 1, 0, 2240}}
+					}
+					verse["audio"] = map[string]interface{}{"url": "recitations/" + recitation + "/001001.mp3", "segments": segments}
+				}
+				writeJSON(w, map[string]interface{}{"verses": []interface{}{verse}})
+			})
+			c := newHandlerClient(t, h, WithAPIVersion(tt.version))
+
+			verses, err := c.Verses(context.Background(), 1, tt.reqOpts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(verses) != 1 {
+				t.Fatalf("got %d verses, want 1", len(verses))
+			}
+			audio := verses[0].Audio
+			if got := audio.URL != "" && len(audio.Segments) == 1; got != tt.wantAudio {
+				t.Errorf("got audio %+v, want audio %t", audio, tt.wantAudio)
+			}
+			if !tt.wantAudio && (audio.URL != "" || audio.Segments != nil) {
+				t.Errorf("got audio %+v, want none", audio)
+			}
+		})
+	}
+}
+
+func TestVersesAll(t *testing.T) {
+	// the pages overlap, 1:3 is on both, and their verses arrive out of order. 1:7 is
+	// sent without an id on both pages.
+	pages := map[string]string{
+		"1": `{"verses": [
+			{"id": 3, "verse_number": 3, "chapter_id": 1, "verse_key": "1:3"},
+			{"id": 1, "verse_number": 1, "chapter_id": 1, "verse_key": "1:1"},
+			{"verse_number": 7, "chapter_id": 1, "verse_key": "1:7"},
+			{"id": 2, "verse_number": 2, "chapter_id": 1, "verse_key": "1:2"}
+		], "meta": {"current_page": 1, "next_page": 2, "prev_page": null, "total_pages": 2, "total_count": 7}}`,
+		"2": `{"verses": [
+			{"id": 6, "verse_number": 6, "chapter_id": 1, "verse_key": "1:6"},
+			{"id": 3, "verse_number": 3, "chapter_id": 1, "verse_key": "1:3"},
+			{"verse_number": 7, "chapter_id": 1, "verse_key": "1:7"},
+			{"id": 5, "verse_number": 5, "chapter_id": 1, "verse_key": "1:5"},
+			{"id": 4, "verse_number": 4, "chapter_id": 1, "verse_key": "1:4"}
+		], "meta": {"current_page": 2, "next_page": null, "prev_page": 1, "total_pages": 2, "total_count": 7}}`,
+	}
+
+	tests := []struct {
+		name     string
+		reqOpts  []VersesReqOptFn
+		wantKeys []string
+	}{
+		{
+			name:     "ordered and deduped",
+			wantKeys: []string{"1:1", "1:2", "1:3", "1:4", "1:5", "1:6", "1:7"},
+		},
+		{
+			name:     "arrival order",
+			reqOpts:  []VersesReqOptFn{VersesKeepArrivalOrder()},
+			wantKeys: []string{"1:3", "1:1", "1:7", "1:2", "1:6", "1:3", "1:7", "1:5", "1:4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &versesStub{intercept: func(w http.ResponseWriter, r *http.Request) bool {
+				body, ok := pages[r.URL.Query().Get("page")]
+				if !ok {
+					t.Errorf("got request for page %q", r.URL.Query().Get("page"))
+					http.NotFound(w, r)
+					return true
+				}
+				w.Write([]byte(body))
+				return true
+			}}
+			c := newHandlerClient(t, stub)
+
+			verses, err := VersesAll(context.Background(), c, 1, tt.reqOpts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys := make([]string, 0, len(verses))
+			for _, v := range verses {
+				keys = append(keys, v.VerseKey)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("got %v, want %v", keys, tt.wantKeys)
+			}
+			if n := stub.requestCount("/api/v3/chapters/1/verses"); n != 2 {
+				t.Errorf("sent %d requests, want one per page", n)
+			}
+		})
+	}
+
+	t.Run("an invalid chapter sends no request", func(t *testing.T) {
+		stub := new(versesStub)
+		c := newHandlerClient(t, stub)
+		if _, err := VersesAll(context.Background(), c, 115); err == nil {
+			t.Error("got no error")
+		}
+		if n := stub.totalRequests(); n != 0 {
+			t.Errorf("sent %d requests", n)
+		}
+	})
+}
+
+func TestVersesMap(t *testing.T) {
+	stub := &versesStub{chapters: []Chapter{{ID: 1, ChapterNumber: 1, VersesCount: 7}}}
+	c := newHandlerClient(t, stub)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		chapter  int
+		reqOpts  []VersesReqOptFn
+		wantKeys []string
+		wantErr  error
+	}{
+		{name: "chapter", chapter: 1, wantKeys: []string{"1:1", "1:2", "1:3", "1:4", "1:5", "1:6", "1:7"}},
+		{name: "page of the chapter", chapter: 1, reqOpts: []VersesReqOptFn{VersesLimit(3), VersesPage(2)}, wantKeys: []string{"1:4", "1:5", "1:6"}},
+		{name: "not found", chapter: 2, wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VersesMap(ctx, c, tt.chapter, tt.reqOpts...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tt.wantKeys) {
+				t.Errorf("got %d verses, want %d", len(got), len(tt.wantKeys))
+			}
+			for _, key := range tt.wantKeys {
+				if v, ok := got[key]; !ok || v.VerseKey != key {
+					t.Errorf("got %+v at %s", v, key)
+				}
+			}
+		})
+	}
+}
+
+func TestVerseByKey(t *testing.T) {
+	stub := &versesStub{chapters: []Chapter{{ID: 2, ChapterNumber: 2, VersesCount: 286}}}
+	c := newHandlerClient(t, stub)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "verse", key: "2:255"},
+		{name: "last verse", key: "2:286"},
+		{name: "verse past the chapter", key: "2:287", wantErr: true},
+		{name: "chapter past the last", key: "115:1", wantErr: true},
+		{name: "no verse", key: "2:", wantErr: true},
+		{name: "verse zero", key: "2:0", wantErr: true},
+		{name: "not a key", key: "ayat al-kursi", wantErr: true},
+		{name: "range", key: "2:255-257", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := stub.totalRequests()
+			v, err := VerseByKey(context.Background(), c, tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got verse %+v, want an error", v)
+				}
+				if n := stub.totalRequests() - before; n != 0 {
+					t.Errorf("sent %d requests for a malformed key", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.VerseKey != tt.key {
+				t.Errorf("got verse %s, want %s", v.VerseKey, tt.key)
+			}
+		})
+	}
+}
+
+func TestVersesBatch(t *testing.T) {
+	stub := &versesStub{chapters: []Chapter{
+		{ID: 1, ChapterNumber: 1, VersesCount: 7},
+		{ID: 2, ChapterNumber: 2, VersesCount: 286},
+	}}
+	c := newHandlerClient(t, stub)
+
+	tests := []struct {
+		name     string
+		keys     []VerseKey
+		wantKeys []string
+		wantErrs int
+		// wantNotFound is whether one of the errors is ErrNotFound.
+		wantNotFound bool
+	}{
+		{
+			name:     "verses",
+			keys:     []VerseKey{{Chapter: 1, Verse: 1}, {Chapter: 2, Verse: 255}, {Chapter: 1, Verse: 7}},
+			wantKeys: []string{"1:1", "1:7", "2:255"},
+		},
+		{
+			name:     "malformed keys are left out",
+			keys:     []VerseKey{{Chapter: 1, Verse: 1}, {Chapter: 1, Verse: 8}, {Chapter: 0, Verse: 1}},
+			wantKeys: []string{"1:1"},
+			wantErrs: 2,
+		},
+		{
+			name:         "failed verses are left out",
+			keys:         []VerseKey{{Chapter: 2, Verse: 1}, {Chapter: 3, Verse: 1}},
+			wantKeys:     []string{"2:1"},
+			wantErrs:     1,
+			wantNotFound: true,
+		},
+		{
+			name:     "no keys",
+			wantKeys: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verses, errs := VersesBatch(context.Background(), c, tt.keys, 2)
+			if len(errs) != tt.wantErrs {
+				t.Errorf("got errors %v, want %d", errs, tt.wantErrs)
+			}
+			if got := errors.Is(MultiError(errs), ErrNotFound); got != tt.wantNotFound {
+				t.Errorf("got errors %v, want not found %t", errs, tt.wantNotFound)
+			}
+
+			keys := make([]string, 0, len(verses))
+			for key, v := range verses {
+				if v.VerseKey != key {
+					t.Errorf("got verse %s at %s", v.VerseKey, key)
+				}
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("got %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+
+	t.Run("a cancelled context returns its error alone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		before := stub.totalRequests()
+		verses, errs := VersesBatch(ctx, c, []VerseKey{{Chapter: 1, Verse: 1}, {Chapter: 1, Verse: 2}}, 1)
+		if len(verses) != 0 || len(errs) != 1 || errs[0] != context.Canceled {
+			t.Errorf("got %v and errors %v, want context.Canceled alone", verses, errs)
+		}
+		if n := stub.totalRequests() - before; n != 0 {
+			t.Errorf("sent %d requests", n)
+		}
+	})
+}
+
+func TestVerse_Text(t *testing.T) {
+	v := Verse{
+		TextMadani:  "madani",
+		TextSimple:  "simple",
+		TextImlaei:  "imlaei",
+		TextIndopak: "",
+	}
+
+	tests := []struct {
+		name     string
+		verse    Verse
+		textType string
+		fallback []string
+		want     string
+	}{
+		{name: "requested type", verse: v, textType: TextTypeSimple, want: "simple"},
+		{name: "requested type over its fallbacks", verse: v, textType: TextTypeImlaei, fallback: []string{TextTypeMadani}, want: "imlaei"},
+		{name: "empty without fallbacks", verse: v, textType: TextTypeIndopak, want: ""},
+		{name: "first fallback", verse: v, textType: TextTypeIndopak, fallback: []string{TextTypeMadani, TextTypeSimple}, want: "madani"},
+		{name: "empty fallback is skipped", verse: v, textType: TextTypeIndopak, fallback: []string{TextTypeUthmani, TextTypeSimple, TextTypeMadani}, want: "simple"},
+		{name: "unknown type falls back", verse: v, textType: "warsh", fallback: []string{TextTypeImlaei}, want: "imlaei"},
+		{name: "every type empty", verse: Verse{}, textType: TextTypeIndopak, fallback: []string{TextTypeMadani, TextTypeSimple}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []TextOptFn
+			if tt.fallback != nil {
+				opts = append(opts, WithTextTypeFallback(tt.fallback...))
+			}
+			if got := tt.verse.Text(tt.textType, opts...); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerseInChapter(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		chapter      int
+		verse        int
+		wantNotFound bool
+		wantRequests int
+	}{
+		{name: "first verse", chapter: 1, verse: 1, wantRequests: 2},
+		{name: "last verse", chapter: 1, verse: 7, wantRequests: 2},
+		{name: "verse past the chapter", chapter: 1, verse: 300, wantNotFound: true, wantRequests: 1},
+		{name: "verse zero", chapter: 1, verse: 0, wantNotFound: true, wantRequests: 1},
+		{name: "chapter that is not served", chapter: 3, verse: 1, wantNotFound: true, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &versesStub{chapters: []Chapter{{ID: 1, ChapterNumber: 1, VersesCount: 7}}}
+			c := newHandlerClient(t, stub)
+
+			v, err := VerseInChapter(ctx, c, tt.chapter, tt.verse)
+			if tt.wantNotFound {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("got %+v with error %v, want ErrNotFound", v, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if v.ChapterID != tt.chapter || v.VerseNumber != tt.verse {
+				t.Errorf("got verse %s, want %d:%d", v.VerseKey, tt.chapter, tt.verse)
+			}
+			if n := stub.totalRequests(); n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+
+	t.Run("a cached chapter validates without a request", func(t *testing.T) {
+		stub := &versesStub{chapters: []Chapter{{ID: 1, ChapterNumber: 1, VersesCount: 7}}}
+		api := newTestBoltCache(t, newHandlerClient(t, stub))
+
+		if _, err := VerseInChapter(ctx, api, 1, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := VerseInChapter(ctx, api, 1, 300); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v, want ErrNotFound", err)
+		}
+		if n := stub.totalRequests(); n != 2 {
+			t.Errorf("sent %d requests, want only those of the first verse", n)
+		}
+	})
+}
+
+func TestGroupVersesByJuz(t *testing.T) {
+	verse := func(key string, juz int) Verse {
+		k, _ := ParseVerseKey(key)
+		return Verse{ChapterID: k.Chapter, VerseNumber: k.Verse, VerseKey: key, JuzNumber: juz}
+	}
+
+	tests := []struct {
+		name       string
+		verses     []Verse
+		want       map[int][]string
+		wantSorted []int
+	}{
+		{name: "no verses", want: map[int][]string{}, wantSorted: []int{}},
+		{
+			name:       "single juz",
+			verses:     []Verse{verse("1:1", 1), verse("1:2", 1)},
+			want:       map[int][]string{1: {"1:1", "1:2"}},
+			wantSorted: []int{1},
+		},
+		{
+			name:       "chapter spanning juz boundaries",
+			verses:     []Verse{verse("2:141", 1), verse("2:142", 2), verse("2:252", 2), verse("2:253", 3)},
+			want:       map[int][]string{1: {"2:141"}, 2: {"2:142", "2:252"}, 3: {"2:253"}},
+			wantSorted: []int{1, 2, 3},
+		},
+		{
+			name:       "verses out of order keep their order",
+			verses:     []Verse{verse("2:253", 3), verse("2:142", 2), verse("2:141", 1), verse("2:143", 2)},
+			want:       map[int][]string{1: {"2:141"}, 2: {"2:142", "2:143"}, 3: {"2:253"}},
+			wantSorted: []int{1, 2, 3},
+		},
+		{
+			name:       "verses without a juz",
+			verses:     []Verse{verse("1:1", 0), verse("2:142", 2)},
+			want:       map[int][]string{0: {"1:1"}, 2: {"2:142"}},
+			wantSorted: []int{0, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := GroupVersesByJuz(tt.verses)
+			got := make(map[int][]string, len(groups))
+			for juz, verses := range groups {
+				for _, v := range verses {
+					got[juz] = append(got[juz], v.VerseKey)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if sorted := SortedJuz(groups); !reflect.DeepEqual(sorted, tt.wantSorted) {
+				t.Errorf("got sorted juz %v, want %v", sorted, tt.wantSorted)
+			}
+		})
+	}
+}
+
+func TestPageLineCount(t *testing.T) {
+	// 2:5 starts at the bottom of page 2 and ends at the top of page 3, where 2:6 follows.
+	verses := []Verse{
+		{VerseKey: "2:5", Words: []Word{
+			{Position: 1, PageNumber: 2, LineNumber: 14},
+			{Position: 2, PageNumber: 2, LineNumber: 15},
+			{Position: 3, PageNumber: 3, LineNumber: 1},
+		}},
+		{VerseKey: "2:6", Words: []Word{
+			{Position: 1, PageNumber: 3, LineNumber: 1},
+			{Position: 2, PageNumber: 3, LineNumber: 2},
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		verses []Verse
+		page   int
+		want   int
+	}{
+		{name: "page the verses end on", verses: verses, page: 2, want: 15},
+		{name: "page the verses continue on", verses: verses, page: 3, want: 2},
+		{name: "page without the verses", verses: verses, page: 4, want: 0},
+		{name: "verses without words", verses: []Verse{{VerseKey: "2:5"}}, page: 2, want: 0},
+		{name: "no verses", page: 2, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PageLineCount(tt.verses, tt.page); got != tt.want {
+				t.Errorf("got %d lines, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerse_PageForWord(t *testing.T) {
+	// 2:5 starts at the bottom of page 2 and ends at the top of page 3.
+	verse := Verse{VerseKey: "2:5", Words: []Word{
+		{Position: 1, PageNumber: 2},
+		{Position: 2, PageNumber: 2},
+		{Position: 3, PageNumber: 3},
+	}}
+
+	tests := []struct {
+		name     string
+		verse    Verse
+		position int
+		wantPage int
+		wantOK   bool
+	}{
+		{name: "word before the page turn", verse: verse, position: 2, wantPage: 2, wantOK: true},
+		{name: "word after the page turn", verse: verse, position: 3, wantPage: 3, wantOK: true},
+		{name: "position past the words", verse: verse, position: 4},
+		{name: "position zero", verse: verse, position: 0},
+		{name: "verse without words", verse: Verse{VerseKey: "2:5", PageNumber: 2}, position: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, ok := tt.verse.PageForWord(tt.position)
+			if page != tt.wantPage || ok != tt.wantOK {
+				t.Errorf("got %d, %t, want %d, %t", page, ok, tt.wantPage, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestVerseTranslationsMulti(t *testing.T) {
+	ctx := context.Background()
+
+	// the stub serves 1:1 with the translations asked for, and no other verse.
+	catalog := map[string]Resource{
+		"20":  {ResourceID: 20, LanguageName: "english", Text: "In the name of Allah, the Entirely Merciful, the Especially Merciful."},
+		"131": {ResourceID: 131, LanguageName: "english", Text: "In the Name of Allah—the Most Compassionate, Most Merciful."},
+		"33":  {ResourceID: 33, LanguageName: "indonesian", Text: "Dengan menyebut nama Allah Yang Maha Pemurah lagi Maha Penyayang."},
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/chapters/1/verses/1" {
+			http.NotFound(w, r)
+			return
+		}
+		verse := Verse{ChapterID: 1, VerseNumber: 1, VerseKey: "1:1", Translations: []Resource{}}
+		for _, id := range r.URL.Query()["translations[]"] {
+			verse.Translations = append(verse.Translations, catalog[id])
+		}
+		writeJSON(w, map[string]interface{}{"verse": verse})
+	})
+	c := newHandlerClient(t, h)
+
+	tests := []struct {
+		name           string
+		verse          int
+		translationIDs []int
+		want           map[string]int
+		wantErr        error
+	}{
+		{name: "different languages", verse: 1, translationIDs: []int{20, 33}, want: map[string]int{"english": 20, "indonesian": 33}},
+		{name: "same language", verse: 1, translationIDs: []int{20, 131}, want: map[string]int{"20": 20, "131": 131}},
+		{name: "same and different languages", verse: 1, translationIDs: []int{20, 131, 33}, want: map[string]int{"20": 20, "131": 131, "indonesian": 33}},
+		{name: "no translations", verse: 1, want: map[string]int{}},
+		{name: "a verse that fails", verse: 2, translationIDs: []int{20, 33}, wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translations, err := VerseTranslationsMulti(ctx, c, 1, tt.verse, tt.translationIDs)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got %v with error %v, want %v", translations, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]int, len(translations))
+			for key, r := range translations {
+				got[key] = r.ResourceID
+				if r.Text == "" {
+					t.Errorf("got %s without its text", key)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitChapterIntoPortions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		portions   int
+		want       [][]int
+		wantErr    bool
+		wantVerses bool
+	}{
+		{name: "uneven portions", portions: 3, want: [][]int{{1, 2, 3}, {4, 5}, {6, 7}}, wantVerses: true},
+		{name: "single portion", portions: 1, want: [][]int{{1, 2, 3, 4, 5, 6, 7}}, wantVerses: true},
+		{name: "a verse a portion", portions: 7, want: [][]int{{1}, {2}, {3}, {4}, {5}, {6}, {7}}, wantVerses: true},
+		{name: "zero portions", portions: 0, wantErr: true},
+		{name: "negative portions", portions: -1, wantErr: true},
+		{name: "more portions than verses", portions: 8, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &versesStub{chapters: []Chapter{{ID: 1, ChapterNumber: 1, VersesCount: 7}}}
+			c := newHandlerClient(t, stub)
+
+			split, err := SplitChapterIntoPortions(ctx, c, 1, tt.portions)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %d portions, want an error", len(split))
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([][]int, 0, len(split))
+			for _, portion := range split {
+				verses := []int{}
+				for _, v := range portion {
+					verses = append(verses, v.VerseNumber)
+				}
+				got = append(got, verses)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if sent := stub.requestCount("/api/v3/chapters/1/verses") > 0; sent != tt.wantVerses {
+				t.Errorf("got verses requested %t, want %t", sent, tt.wantVerses)
+			}
+		})
+	}
+
+	t.Run("invalid chapter", func(t *testing.T) {
+		if _, err := SplitChapterIntoPortions(ctx, New(), 115, 1); err == nil {
+			t.Error("got no error")
+		}
+	})
+}
+
+func TestChapterWordCount(t *testing.T) {
+	ctx := context.Background()
+
+	// withWords serves the verses of al-ikhlas with their words, each verse v with v words
+	// and an end of verse marker, 10 words in all.
+	withWords := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/api/v3/chapters/112/verses" {
+			return false
+		}
+		if r.URL.Query().Get("words") == "false" {
+			t.Error("got verses requested without their words")
+		}
+		verses := []Verse{}
+		for v := 1; v <= 4; v++ {
+			verse := Verse{ChapterID: 112, VerseNumber: v, VerseKey: verseKey(112, v)}
+			for p := 1; p <= v; p++ {
+				verse.Words = append(verse.Words, Word{Position: p, CharType: charTypeWord})
+			}
+			verse.Words = append(verse.Words, Word{Position: v + 1, CharType: "end"})
+			verses = append(verses, verse)
+		}
+		writeJSON(w, map[string]interface{}{"verses": verses})
+		return true
+	}
+	chapters := []Chapter{{ID: 112, ChapterNumber: 112, VersesCount: 4}}
+
+	tests := []struct {
+		name         string
+		api          func(t *testing.T, stub *versesStub) QuranAPI
+		wantRequests int
+	}{
+		{
+			name:         "client",
+			api:          func(t *testing.T, stub *versesStub) QuranAPI { return newHandlerClient(t, stub) },
+			wantRequests: 4,
+		},
+		{
+			name:         "bolt cache",
+			api:          func(t *testing.T, stub *versesStub) QuranAPI { return newTestBoltCache(t, newHandlerClient(t, stub)) },
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &versesStub{chapters: chapters, intercept: withWords}
+			api := tt.api(t, stub)
+
+			// the second count is served from the cache of a cached api.
+			for i := 0; i < 2; i++ {
+				count, err := api.ChapterWordCount(ctx, 112)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if count != 10 {
+					t.Errorf("got %d words, want 10", count)
+				}
+			}
+			if n := stub.totalRequests(); n != tt.wantRequests {
+				t.Errorf("sent %d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+
+	t.Run("invalid chapter", func(t *testing.T) {
+		stub := &versesStub{chapters: chapters}
+		if _, err := newHandlerClient(t, stub).ChapterWordCount(ctx, 0); err == nil {
+			t.Error("got no error")
+		}
+		if n := stub.totalRequests(); n != 0 {
+			t.Errorf("sent %d requests for an invalid chapter", n)
+		}
+	})
+}
+
+func TestVerse_ContentKey(t *testing.T) {
+	// madani is 1:1 fetched with the madani mushaf, every other verse is compared against it.
+	madani := Verse{
+		VerseKey:     "1:1",
+		PageNumber:   1,
+		TextMadani:   "بِسْمِ اللَّهِ الرَّحْمَٰنِ الرَّحِيمِ",
+		TextSimple:   "بسم الله الرحمن الرحيم",
+		Words:        []Word{{Position: 1, PageNumber: 1, CodeV3: "ﭑ"}},
+		Translations: []Resource{{ResourceID: 20, Text: "In the name of Allah, the Entirely Merciful, the Especially Merciful."}},
+	}
+	indopak := madani
+	indopak.PageNumber = 2
+	indopak.TextMadani = ""
+	indopak.TextIndopak = "بِسۡمِ اللّٰہِ الرَّحۡمٰنِ الرَّحِیۡمِ"
+	indopak.Words = []Word{{Position: 1, PageNumber: 2, CodeV3: "ﱁ"}}
+	translated := madani
+	translated.Translations = []Resource{{ResourceID: 131, Text: "In the Name of Allah—the Most Compassionate, Most Merciful."}}
+	otherText := madani
+	otherText.TextSimple = "الحمد لله رب العالمين"
+	otherKey := madani
+	otherKey.VerseKey = "27:30"
+	madaniOnly := madani
+	madaniOnly.TextSimple = ""
+
+	tests := []struct {
+		name     string
+		verse    Verse
+		wantSame bool
+	}{
+		{name: "same verse", verse: madani, wantSame: true},
+		{name: "another mushaf", verse: indopak, wantSame: true},
+		// only the verse key and text are hashed, translations are not part of the content.
+		{name: "other translations", verse: translated, wantSame: true},
+		{name: "other text", verse: otherText},
+		{name: "other verse key", verse: otherKey},
+		{name: "text falling back to the madani text", verse: madaniOnly},
+	}
+
+	want := madani.ContentKey()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.verse.ContentKey(); (got == want) != tt.wantSame {
+				t.Errorf("got key %s equal to %s %t, want %t", got, want, got == want, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestInternTranslations(t *testing.T) {
+	// translation returns a translation with freshly allocated strings, so that equal strings
+	// only share their storage once interned.
+	translation := func(id int, text, resource, language string) Resource {
+		return Resource{ResourceID: id, Text: strings.Clone(text), ResourceName: strings.Clone(resource), LanguageName: strings.Clone(language)}
+	}
+	const basmala = "In the name of Allah, the Entirely Merciful, the Especially Merciful."
+
+	tests := []struct {
+		name   string
+		verses []Verse
+		// want are the number of distinct backing arrays of the texts, resource names and
+		// language names once interned.
+		wantTexts, wantResources, wantLanguages int
+	}{
+		{name: "no verses"},
+		{
+			name: "repeated basmala",
+			verses: []Verse{
+				{VerseKey: "1:1", Translations: []Resource{translation(20, basmala, "Saheeh International", "english")}},
+				{VerseKey: "27:30", Translations: []Resource{translation(20, "Indeed, it is from Solomon, and indeed, it reads: "+basmala, "Saheeh International", "english")}},
+				{VerseKey: "2:1", Translations: []Resource{translation(20, basmala, "Saheeh International", "english")}},
+			},
+			wantTexts: 2, wantResources: 1, wantLanguages: 1,
+		},
+		{
+			name: "translators of the same language",
+			verses: []Verse{
+				{VerseKey: "1:1", Translations: []Resource{
+					translation(20, basmala, "Saheeh International", "english"),
+					translation(131, "In the Name of Allah—the Most Compassionate, Most Merciful.", "Dr. Mustafa Khattab", "english"),
+				}},
+				{VerseKey: "2:1", Translations: []Resource{
+					translation(20, basmala, "Saheeh International", "english"),
+					translation(131, "In the Name of Allah—the Most Compassionate, Most Merciful.", "Dr. Mustafa Khattab", "english"),
+				}},
+			},
+			wantTexts: 2, wantResources: 2, wantLanguages: 1,
+		},
+		{name: "verses without translations", verses: []Verse{{VerseKey: "1:1"}, {VerseKey: "1:2"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []Verse
+			for _, v := range tt.verses {
+				v.Translations = append([]Resource(nil), v.Translations...)
+				want = append(want, v)
+			}
+
+			InternTranslations(tt.verses)
+			if !reflect.DeepEqual(tt.verses, want) {
+				t.Errorf("got %+v, want the values unchanged %+v", tt.verses, want)
+			}
+
+			texts, resources, languages := map[*byte]bool{}, map[*byte]bool{}, map[*byte]bool{}
+			for _, v := range tt.verses {
+				for _, r := range v.Translations {
+					texts[unsafe.StringData(r.Text)] = true
+					resources[unsafe.StringData(r.ResourceName)] = true
+					languages[unsafe.StringData(r.LanguageName)] = true
+				}
+			}
+			if len(texts) != tt.wantTexts || len(resources) != tt.wantResources || len(languages) != tt.wantLanguages {
+				t.Errorf("got %d texts, %d resource names and %d language names, want %d, %d and %d",
+					len(texts), len(resources), len(languages), tt.wantTexts, tt.wantResources, tt.wantLanguages)
+			}
+		})
+	}
+}
+
+func TestVersesWithChapterName(t *testing.T) {
+	ctx := context.Background()
+	chapters := []Chapter{
+		{ID: 1, ChapterNumber: 1, NameSimple: "Al-Fatihah", VersesCount: 7},
+		{ID: 112, ChapterNumber: 112, NameSimple: "Al-Ikhlas", VersesCount: 4},
+	}
+
+	tests := []struct {
+		name       string
+		chapter    int
+		wantName   string
+		wantVerses int
+		wantErr    bool
+	}{
+		{name: "al-fatihah", chapter: 1, wantName: "Al-Fatihah", wantVerses: 7},
+		{name: "al-ikhlas", chapter: 112, wantName: "Al-Ikhlas", wantVerses: 4},
+		{name: "chapter that is not served", chapter: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &versesStub{chapters: chapters}
+			c := newHandlerClient(t, stub)
+
+			verses, name, err := VersesWithChapterName(ctx, c, tt.chapter)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %q with %d verses, want an error", name, len(verses))
+				}
+				if n := stub.totalRequests(); n != 1 {
+					t.Errorf("sent %d requests, want only the chapter's", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.wantName {
+				t.Errorf("got name %q, want %q", name, tt.wantName)
+			}
+			if len(verses) != tt.wantVerses {
+				t.Fatalf("got %d verses, want %d", len(verses), tt.wantVerses)
+			}
+			for i, v := range verses {
+				if v.ChapterID != tt.chapter || v.VerseNumber != i+1 {
+					t.Errorf("got verse %s at %d", v.VerseKey, i)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_VerseTextTypes(t *testing.T) {
+	const (
+		uthmani       = "قُلْ هُوَ ٱللَّهُ أَحَدٌ"
+		uthmaniSimple = "قل هو الله احد"
+		imlaei        = "قُلْ هُوَ اللَّهُ أَحَدٌ"
+	)
+	// the verse and its first word are sent with every script variant.
+	const verse = `{"id": 6222, "verse_number": 1, "chapter_id": 112, "verse_key": "112:1",
+		"text_uthmani": "قُلْ هُوَ ٱللَّهُ أَحَدٌ", "text_uthmani_simple": "قل هو الله احد", "text_imlaei": "قُلْ هُوَ اللَّهُ أَحَدٌ",
+		"words": [{"position": 1, "char_type": "word", "char_type_name": "word", "text_uthmani": "قُلْ", "text_uthmani_simple": "قل", "text_imlaei": "قُلْ"}]}`
+
+	tests := []struct {
+		name              string
+		version           string
+		path              string
+		wantWordUthSimple string
+	}{
+		{name: "v3", version: "v3", path: "/api/v3/chapters/112/verses/1", wantWordUthSimple: "قل"},
+		// v4 words are not sent in the simple uthmani script.
+		{name: "v4", version: "v4", path: "/api/v4/verses/by_key/112:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := v4Handler(t, tt.path, `{"verse": `+verse+`}`, nil)
+			c := newHandlerClient(t, h, WithAPIVersion(tt.version))
+
+			v, err := c.Verse(context.Background(), 112, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for textType, want := range map[string]string{TextTypeUthmani: uthmani, TextTypeUthmaniSimple: uthmaniSimple, TextTypeImlaei: imlaei} {
+				if got := v.Text(textType); got != want {
+					t.Errorf("got %s text %q, want %q", textType, got, want)
+				}
+			}
+
+			if len(v.Words) != 1 {
+				t.Fatalf("got %d words, want 1", len(v.Words))
+			}
+			w := v.Words[0]
+			if w.TextUthmani != "قُلْ" || w.TextImlaei != "قُلْ" || w.TextUthmaniSimple != tt.wantWordUthSimple {
+				t.Errorf("got word texts %q, %q and %q", w.TextUthmani, w.TextUthmaniSimple, w.TextImlaei)
+			}
+		})
+	}
+}
+
+func TestVerse_MediaByID(t *testing.T) {
+	const body = `{"verse": {"id": 262, "verse_number": 255, "chapter_id": 2, "verse_key": "2:255", "media_contents": [
+		{"resource_id": 2, "url": "https://youtube.com/embed/a", "provider": "youtube", "author_name": "Nouman Ali Khan"},
+		{"resource_id": 5, "url": "https://youtube.com/embed/b", "provider": "youtube", "author_name": "Bayyinah"}
+	]}}`
+	verse, err := newTestClient(t, body).Verse(context.Background(), 2, 255, VersesMedia([]int{2, 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		verse   Verse
+		id      int
+		wantURL string
+		wantOK  bool
+	}{
+		{name: "first resource", verse: verse, id: 2, wantURL: "https://youtube.com/embed/a", wantOK: true},
+		{name: "second resource", verse: verse, id: 5, wantURL: "https://youtube.com/embed/b", wantOK: true},
+		{name: "resource not returned", verse: verse, id: 3},
+		{name: "verse without media", verse: Verse{VerseKey: "2:255"}, id: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ok := tt.verse.MediaByID(tt.id)
+			if ok != tt.wantOK || m.URL != tt.wantURL {
+				t.Errorf("got %+v, %t, want url %q, %t", m, ok, tt.wantURL, tt.wantOK)
+			}
+			if ok && m.ID != tt.id {
+				t.Errorf("got media of resource %d, want %d", m.ID, tt.id)
+			}
+		})
+	}
+}